@@ -0,0 +1,242 @@
+package wxr
+
+import (
+	"mime"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PostTypeAttachment is the PostType of items that describe an uploaded
+// file in the media library.
+const PostTypeAttachment = "attachment"
+
+// Postmeta keys WordPress stores alongside attachments.
+const (
+	metaAttachedFile       = "_wp_attached_file"
+	metaAttachmentMetadata = "_wp_attachment_metadata"
+	metaAttachmentAlt      = "_wp_attachment_image_alt"
+)
+
+// An Attachment is a file in the WordPress media library.
+type Attachment struct {
+	// ID is the attachment's own post ID.
+	ID int
+
+	Title string
+
+	// URL is where the original file was uploaded.
+	URL string
+
+	// Parent is the ID of the post the file was uploaded to, or 0 if it
+	// isn't attached to one.
+	Parent int
+
+	// MIMEType is derived from the file's extension, since WXR doesn't
+	// export it.
+	MIMEType string
+
+	// File is the path of the file relative to the uploads directory,
+	// e.g. 2021/08/photo.jpg.
+	File string
+
+	// AltText is the image's alternative text, if any.
+	AltText string
+
+	// Width and Height are the dimensions of the original image, or zero
+	// for files that aren't images.
+	Width  int
+	Height int
+
+	// Sizes holds the resized copies WordPress generated for an image,
+	// keyed by size name (thumbnail, medium, large, etc.).
+	Sizes map[string]ImageSize
+}
+
+// An ImageSize is a resized copy of an image attachment.
+type ImageSize struct {
+	Name     string
+	File     string
+	Width    int
+	Height   int
+	MIMEType string
+}
+
+// URL returns the address of the resized copy, which WordPress stores
+// next to the original.
+func (s ImageSize) URL(a *Attachment) string {
+	i := strings.LastIndexByte(a.URL, '/')
+	if i < 0 || s.File == "" {
+		return ""
+	}
+	return a.URL[:i+1] + s.File
+}
+
+// Attachment returns the media library view of the item. It reports false
+// if the item isn't an attachment.
+//
+// The image sizes are decoded from the serialized PHP in the item's
+// _wp_attachment_metadata postmeta. If that is missing or can't be
+// decoded the sizes and dimensions are left empty.
+func (it Item) Attachment() (Attachment, bool) {
+	if it.PostType != PostTypeAttachment {
+		return Attachment{}, false
+	}
+
+	a := Attachment{
+		ID:     it.PostID,
+		Title:  it.Title,
+		URL:    it.AttachmentURL,
+		Parent: it.PostParent,
+	}
+	a.File, _ = it.Meta(metaAttachedFile)
+	a.AltText, _ = it.Meta(metaAttachmentAlt)
+
+	for _, m := range it.MetaKVs {
+		if m.Key != metaAttachmentMetadata {
+			continue
+		}
+
+		var md attachmentMetadata
+		if err := m.Decode(&md); err == nil {
+			a.setMetadata(md)
+		}
+		break
+	}
+
+	name := a.File
+	if name == "" {
+		name = a.URL
+	}
+	a.MIMEType = mimeType(name)
+
+	return a, true
+}
+
+// attachmentMetadata is the part of the serialized
+// _wp_attachment_metadata that the media library is interested in.
+type attachmentMetadata struct {
+	Width  int                  `php:"width"`
+	Height int                  `php:"height"`
+	File   string               `php:"file"`
+	Sizes  map[string]imageSize `php:"sizes"`
+}
+
+type imageSize struct {
+	File     string `php:"file"`
+	Width    int    `php:"width"`
+	Height   int    `php:"height"`
+	MIMEType string `php:"mime-type"`
+}
+
+// setMetadata fills in the dimensions and sizes from decoded
+// _wp_attachment_metadata.
+func (a *Attachment) setMetadata(md attachmentMetadata) {
+	a.Width = md.Width
+	a.Height = md.Height
+	if a.File == "" {
+		a.File = md.File
+	}
+
+	if len(md.Sizes) == 0 {
+		return
+	}
+
+	a.Sizes = make(map[string]ImageSize, len(md.Sizes))
+	for name, size := range md.Sizes {
+		s := ImageSize{
+			Name:     name,
+			File:     size.File,
+			Width:    size.Width,
+			Height:   size.Height,
+			MIMEType: size.MIMEType,
+		}
+		if s.MIMEType == "" {
+			s.MIMEType = mimeType(s.File)
+		}
+		a.Sizes[name] = s
+	}
+}
+
+func mimeType(name string) string {
+	ext := strings.ToLower(path.Ext(name))
+	if ext == "" {
+		return ""
+	}
+	t := mime.TypeByExtension(ext)
+	if i := strings.IndexByte(t, ';'); i >= 0 {
+		t = t[:i]
+	}
+	return t
+}
+
+// A MediaLibrary indexes the attachments in a channel.
+type MediaLibrary struct {
+	attachments []*Attachment
+	byID        map[int]*Attachment
+	byURL       map[string]*Attachment
+	byParent    map[int][]*Attachment
+}
+
+// MediaLibrary builds an index of the channel's attachments. It reflects
+// the channel's items at the time it is called.
+func (c *Channel) MediaLibrary() *MediaLibrary {
+	m := &MediaLibrary{
+		byID:     make(map[int]*Attachment),
+		byURL:    make(map[string]*Attachment),
+		byParent: make(map[int][]*Attachment),
+	}
+
+	for _, it := range c.Items {
+		a, ok := it.Attachment()
+		if !ok {
+			continue
+		}
+
+		ap := &a
+		m.attachments = append(m.attachments, ap)
+		m.byID[a.ID] = ap
+		if a.URL != "" {
+			m.byURL[a.URL] = ap
+		}
+		for _, s := range a.Sizes {
+			if u := s.URL(ap); u != "" {
+				if _, ok := m.byURL[u]; !ok {
+					m.byURL[u] = ap
+				}
+			}
+		}
+		if a.Parent != 0 {
+			m.byParent[a.Parent] = append(m.byParent[a.Parent], ap)
+		}
+	}
+
+	return m
+}
+
+// Attachments returns every attachment in the library, in the order they
+// appear in the export.
+func (m *MediaLibrary) Attachments() []*Attachment {
+	return m.attachments
+}
+
+// ByID returns the attachment whose post ID is id.
+func (m *MediaLibrary) ByID(id int) (*Attachment, bool) {
+	a, ok := m.byID[id]
+	return a, ok
+}
+
+// ByURL returns the attachment that was uploaded to u. The URLs of its
+// resized copies resolve to the same attachment.
+func (m *MediaLibrary) ByURL(u string) (*Attachment, bool) {
+	a, ok := m.byURL[u]
+	return a, ok
+}
+
+// AttachedTo returns the attachments uploaded to the post whose ID is
+// parent, ordered by ID.
+func (m *MediaLibrary) AttachedTo(parent int) []*Attachment {
+	as := append([]*Attachment(nil), m.byParent[parent]...)
+	sort.Slice(as, func(i, j int) bool { return as[i].ID < as[j].ID })
+	return as
+}
@@ -0,0 +1,130 @@
+package wxr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const attachmentMetadataFixture = `a:5:{s:5:"width";i:1200;s:6:"height";i:800;s:4:"file";s:17:"2021/08/photo.jpg";` +
+	`s:5:"sizes";a:2:{s:9:"thumbnail";a:4:{s:4:"file";s:17:"photo-150x150.jpg";s:5:"width";i:150;s:6:"height";i:150;s:9:"mime-type";s:10:"image/jpeg";}` +
+	`s:6:"medium";a:4:{s:4:"file";s:17:"photo-300x200.jpg";s:5:"width";i:300;s:6:"height";i:200;s:9:"mime-type";s:10:"image/jpeg";}}` +
+	`s:10:"image_meta";a:1:{s:7:"caption";s:0:"";}}`
+
+var attachmentItemFragment = `
+	<item>
+		<title>photo</title>
+		<wp:post_id>20</wp:post_id>
+		<wp:post_parent>9</wp:post_parent>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://example.com/wp-content/uploads/2021/08/photo.jpg]]></wp:attachment_url>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_wp_attached_file]]></wp:meta_key>
+			<wp:meta_value><![CDATA[2021/08/photo.jpg]]></wp:meta_value>
+		</wp:postmeta>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_wp_attachment_metadata]]></wp:meta_key>
+			<wp:meta_value><![CDATA[` + attachmentMetadataFixture + `]]></wp:meta_value>
+		</wp:postmeta>
+		<wp:postmeta>
+			<wp:meta_key><![CDATA[_wp_attachment_image_alt]]></wp:meta_key>
+			<wp:meta_value><![CDATA[A photo]]></wp:meta_value>
+		</wp:postmeta>
+	</item>
+	<item>
+		<title>manual</title>
+		<wp:post_id>21</wp:post_id>
+		<wp:post_type><![CDATA[attachment]]></wp:post_type>
+		<wp:attachment_url><![CDATA[https://example.com/wp-content/uploads/2021/08/manual.pdf]]></wp:attachment_url>
+	</item>
+`
+
+func TestItemAttachment(t *testing.T) {
+	rss, err := Decode(strings.NewReader(wrapChannel(attachmentItemFragment + itemValidFragment)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	items := rss.Channel.Items
+
+	tests := []struct {
+		name   string
+		in     Item
+		want   Attachment
+		wantOK bool
+	}{
+		{"image", items[0], Attachment{
+			ID:       20,
+			Title:    "photo",
+			URL:      "https://example.com/wp-content/uploads/2021/08/photo.jpg",
+			Parent:   9,
+			MIMEType: "image/jpeg",
+			File:     "2021/08/photo.jpg",
+			AltText:  "A photo",
+			Width:    1200,
+			Height:   800,
+			Sizes: map[string]ImageSize{
+				"thumbnail": {Name: "thumbnail", File: "photo-150x150.jpg", Width: 150, Height: 150, MIMEType: "image/jpeg"},
+				"medium":    {Name: "medium", File: "photo-300x200.jpg", Width: 300, Height: 200, MIMEType: "image/jpeg"},
+			},
+		}, true},
+		{"without metadata", items[1], Attachment{
+			ID:       21,
+			Title:    "manual",
+			URL:      "https://example.com/wp-content/uploads/2021/08/manual.pdf",
+			MIMEType: "application/pdf",
+		}, true},
+		{"not an attachment", items[2], Attachment{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.in.Attachment()
+			if ok != tt.wantOK {
+				t.Fatalf("Attachment() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Attachment() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMediaLibrary(t *testing.T) {
+	rss, err := Decode(strings.NewReader(wrapChannel(attachmentItemFragment + itemValidFragment)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	lib := rss.Channel.MediaLibrary()
+
+	if got := len(lib.Attachments()); got != 2 {
+		t.Errorf("Attachments() has %d attachments, want 2", got)
+	}
+
+	if a, ok := lib.ByID(21); !ok || a.Title != "manual" {
+		t.Errorf("ByID(21) = %+v, %v, want manual", a, ok)
+	}
+	if _, ok := lib.ByID(9); ok {
+		t.Errorf("ByID(9) found a non-attachment item")
+	}
+
+	for _, u := range []string{
+		"https://example.com/wp-content/uploads/2021/08/photo.jpg",
+		"https://example.com/wp-content/uploads/2021/08/photo-150x150.jpg",
+	} {
+		if a, ok := lib.ByURL(u); !ok || a.ID != 20 {
+			t.Errorf("ByURL(%q) = %+v, %v, want attachment 20", u, a, ok)
+		}
+	}
+
+	if got := lib.AttachedTo(9); len(got) != 1 || got[0].ID != 20 {
+		t.Errorf("AttachedTo(9) = %+v, want attachment 20", got)
+	}
+}
+
+func TestEncodeAttachmentURL(t *testing.T) {
+	first, second, _ := roundTrip(t, wrapChannel(attachmentItemFragment))
+	if got, want := second.Channel.Items[0].AttachmentURL, first.Channel.Items[0].AttachmentURL; got != want || got == "" {
+		t.Errorf("AttachmentURL after round trip = %q, want %q", got, want)
+	}
+}
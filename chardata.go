@@ -0,0 +1,217 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	cdataOpen  = "<![CDATA["
+	cdataClose = "]]>"
+
+	// cdataEscapedClose is how a "]]>" inside a CDATA section is written,
+	// by ending the section and starting a new one between the "]]" and
+	// the ">".
+	cdataEscapedClose = "]]]]><![CDATA[>"
+)
+
+// cleanCharData removes any CDATA wrappers that survived XML decoding.
+//
+// Some exports, particularly ones that passed through plugins which wrap
+// values in CDATA themselves, nest one CDATA section inside another. The
+// XML decoder only removes the outer one, leaving a literal "<![CDATA["
+// and "]]>" around the value. Wrappers are removed for as long as the
+// value (ignoring surrounding whitespace) both starts and ends with one;
+// anything else is returned unchanged.
+func cleanCharData(s string) string {
+	for {
+		t := strings.TrimSpace(s)
+		if len(t) < len(cdataOpen)+len(cdataClose) ||
+			!strings.HasPrefix(t, cdataOpen) || !strings.HasSuffix(t, cdataClose) {
+			return s
+		}
+
+		t = t[len(cdataOpen) : len(t)-len(cdataClose)]
+		s = strings.Replace(t, cdataEscapedClose, cdataClose, -1)
+	}
+}
+
+// cleanStrings applies cleanCharData to every string field of the struct
+// v points to.
+func cleanStrings(v interface{}) {
+	rv := reflect.ValueOf(v).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.Kind() == reflect.String && f.CanSet() {
+			f.SetString(cleanCharData(f.String()))
+		}
+	}
+}
+
+// decodeString decodes the character data of the element start into s.
+func decodeString(d *xml.Decoder, start xml.StartElement, s *string) error {
+	var raw string
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+	*s = cleanCharData(raw)
+	return nil
+}
+
+// decodeInt decodes the character data of the element start into n. An
+// empty element decodes as zero.
+func decodeInt(d *xml.Decoder, start xml.StartElement, n *int) error {
+	var s string
+	if err := decodeString(d, start, &s); err != nil {
+		return err
+	}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*n = 0
+		return nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+// The types below are decoded with their struct tags and then have their
+// string fields cleaned. Each converts itself to a local type without
+// methods so that DecodeElement doesn't recurse back into UnmarshalXML.
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (a *Author) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type author Author
+	if err := d.DecodeElement((*author)(a), &start); err != nil {
+		return err
+	}
+	cleanStrings(a)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (c *Category) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type category Category
+	if err := d.DecodeElement((*category)(c), &start); err != nil {
+		return err
+	}
+	cleanStrings(c)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (t *Tag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type tag Tag
+	if err := d.DecodeElement((*tag)(t), &start); err != nil {
+		return err
+	}
+	cleanStrings(t)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (t *Term) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type term Term
+	if err := d.DecodeElement((*term)(t), &start); err != nil {
+		return err
+	}
+	cleanStrings(t)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (m *TermMeta) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type termMeta TermMeta
+	if err := d.DecodeElement((*termMeta)(m), &start); err != nil {
+		return err
+	}
+	cleanStrings(m)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (c *Comment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type comment Comment
+	if err := d.DecodeElement((*comment)(c), &start); err != nil {
+		return err
+	}
+	cleanStrings(c)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (m *CommentMeta) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type commentMeta CommentMeta
+	if err := d.DecodeElement((*commentMeta)(m), &start); err != nil {
+		return err
+	}
+	cleanStrings(m)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (m *PostMeta) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type postMeta PostMeta
+	if err := d.DecodeElement((*postMeta)(m), &start); err != nil {
+		return err
+	}
+	cleanStrings(m)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (c *ItemCategory) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type itemCategory ItemCategory
+	if err := d.DecodeElement((*itemCategory)(c), &start); err != nil {
+		return err
+	}
+	cleanStrings(c)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (g *GUID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type guid GUID
+	if err := d.DecodeElement((*guid)(g), &start); err != nil {
+		return err
+	}
+	cleanStrings(g)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (c *Content) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type content Content
+	if err := d.DecodeElement((*content)(c), &start); err != nil {
+		return err
+	}
+	cleanStrings(c)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (e *Excerpt) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type excerpt Excerpt
+	if err := d.DecodeElement((*excerpt)(e), &start); err != nil {
+		return err
+	}
+	cleanStrings(e)
+	return nil
+}
+
+// UnmarshalXML implements xml.Unmarshaler.
+func (s *Site) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type site Site
+	if err := d.DecodeElement((*site)(s), &start); err != nil {
+		return err
+	}
+	cleanStrings(s)
+	return nil
+}
@@ -0,0 +1,117 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestCleanCharData(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "publish", "publish"},
+		{"empty", "", ""},
+		{"wrapped", "<![CDATA[publish]]>", "publish"},
+		{"wrapped with whitespace", "\n\t<![CDATA[publish]]>\n", "publish"},
+		{"double wrapped", "<![CDATA[<![CDATA[publish]]>]]>", "publish"},
+		{"escaped close inside wrapper", "<![CDATA[a ]]]]><![CDATA[> b]]>", "a ]]> b"},
+		{"unterminated", "<![CDATA[publish", "<![CDATA[publish"},
+		{"wrapper in the middle", "a <![CDATA[b]]> c", "a <![CDATA[b]]> c"},
+		{"whitespace preserved when unwrapped", "  text  ", "  text  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanCharData(tt.in); got != tt.want {
+				t.Errorf("cleanCharData(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// doubleWrapped is a value wrapped in two CDATA sections the way some
+// plugins export it. The inner section's "]]>" has to be escaped, so the
+// XML decoder leaves a literal "<![CDATA[publish]]>" behind.
+func doubleWrapped(s string) string {
+	return "<![CDATA[<![CDATA[" + s + "]]]]><![CDATA[>]]>"
+}
+
+func TestDecodeDoubleWrappedCharData(t *testing.T) {
+	in := wrapChannel(`
+	<wp:author>
+		<wp:author_id>1</wp:author_id>
+		<wp:author_login>` + doubleWrapped("admin") + `</wp:author_login>
+	</wp:author>
+	<wp:category>
+		<wp:category_nicename>` + doubleWrapped("news") + `</wp:category_nicename>
+	</wp:category>
+	<wp:term>
+		<wp:term_slug>` + doubleWrapped("series-a") + `</wp:term_slug>
+	</wp:term>
+	<item>
+		<title>` + doubleWrapped("Title") + `</title>
+		<dc:creator>` + doubleWrapped("admin") + `</dc:creator>
+		<content:encoded>` + doubleWrapped("<p>Hi</p>") + `</content:encoded>
+		<wp:post_id>` + doubleWrapped("12") + `</wp:post_id>
+		<wp:post_name>` + doubleWrapped("hello") + `</wp:post_name>
+		<wp:status>` + doubleWrapped("publish") + `</wp:status>
+		<wp:post_type>` + doubleWrapped("post") + `</wp:post_type>
+		<category domain="category" nicename="news">` + doubleWrapped("News") + `</category>
+		<wp:postmeta>
+			<wp:meta_key>` + doubleWrapped("_edit_last") + `</wp:meta_key>
+			<wp:meta_value>` + doubleWrapped("1") + `</wp:meta_value>
+		</wp:postmeta>
+		<wp:comment>
+			<wp:comment_author>` + doubleWrapped("Bob") + `</wp:comment_author>
+			<wp:commentmeta>
+				<wp:meta_key>` + doubleWrapped("rating") + `</wp:meta_key>
+			</wp:commentmeta>
+		</wp:comment>
+	</item>`)
+
+	streamed, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var unmarshaled RSS
+	if err := xml.Unmarshal([]byte(in), &unmarshaled); err != nil {
+		t.Fatalf("xml.Unmarshal failed: %v", err)
+	}
+
+	for _, rss := range []*RSS{streamed, &unmarshaled} {
+		c := rss.Channel
+		it := c.Items[0]
+
+		for _, tt := range []struct {
+			field     string
+			got, want string
+		}{
+			{"Author.Login", c.Authors[0].Login, "admin"},
+			{"Category.NiceName", c.Categories[0].NiceName, "news"},
+			{"Term.Slug", c.Terms[0].Slug, "series-a"},
+			{"Item.Title", it.Title, "Title"},
+			{"Item.Creator", it.Creator, "admin"},
+			{"Item.Content", it.Content.Data, "<p>Hi</p>"},
+			{"Item.PostName", it.PostName, "hello"},
+			{"Item.RawStatus", it.RawStatus, "publish"},
+			{"Item.PostType", it.PostType, "post"},
+			{"ItemCategory.Name", it.Category[0].Name, "News"},
+			{"PostMeta.Key", it.MetaKVs[0].Key, "_edit_last"},
+			{"PostMeta.Value", it.MetaKVs[0].Value, "1"},
+			{"Comment.Author", it.Comments[0].Author, "Bob"},
+			{"CommentMeta.Key", it.Comments[0].MetaKVs[0].Key, "rating"},
+		} {
+			if tt.got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+			}
+		}
+
+		if it.PostID != 12 {
+			t.Errorf("Item.PostID = %d, want 12", it.PostID)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// comment is the representation of a WordPress comment that is written
+// to a post's comment data file.
+type comment struct {
+	ID        int    `json:"id"`
+	Parent    int    `json:"parent,omitempty"`
+	Author    string `json:"author"`
+	AuthorURL string `json:"author_url,omitempty"`
+	Date      string `json:"date"`
+	Content   string `json:"content"`
+}
+
+// writeComments saves the approved comments on item to a JSON data file
+// named after the post, so that the generator's templates can render the
+// comment threads. Pingbacks, unapproved and spam comments are left out.
+func writeComments(basename string, item wxr.Item) {
+	var comments []comment
+	for _, c := range item.Comments {
+		if c.Approved != "1" {
+			continue
+		}
+		if c.Type == "pingback" || c.Type == "trackback" {
+			continue
+		}
+
+		date := c.DateGMT
+		if date == "" {
+			date = c.Date
+		}
+
+		comments = append(comments, comment{
+			ID:        c.ID,
+			Parent:    c.Parent,
+			Author:    c.Author,
+			AuthorURL: c.AuthorURL,
+			Date:      date,
+			Content:   c.Content,
+		})
+	}
+
+	if len(comments) == 0 {
+		return
+	}
+
+	path := commentsDir(*outputDir)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		log.Printf("failed to make output directory %q: %v", path, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s/%s.json", path, basename)
+	file, err := os.Create(filename)
+	if err != nil {
+		log.Printf("unable to create file %q: %v", filename, err)
+		return
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(comments); err != nil {
+		log.Printf("failed to write comments for %q: %v", item.Title, err)
+		return
+	}
+	log.Printf("%q comments => %q", item.Title, filename)
+}
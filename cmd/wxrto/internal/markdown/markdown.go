@@ -1,6 +1,8 @@
 package markdown
 
 import (
+	"strings"
+
 	"golang.org/x/net/html"
 )
 
@@ -22,6 +24,34 @@ const (
 	NodeUnorderedList
 	NodeOrderedList
 	NodeListItem
+	NodeLineBreak
+
+	// NodeBlock is a container, such as <div> or the document itself,
+	// whose children are laid out as blocks rather than run together.
+	NodeBlock
+
+	// NodeBlockquote is a quotation, including WordPress's quote and pull
+	// quote blocks. Its children are laid out as blocks.
+	NodeBlockquote
+
+	// NodeCitation is the attribution of a quotation, given by a <cite>
+	// in a <blockquote>.
+	NodeCitation
+
+	// NodeTable is a table that Markdown can represent. Its rows are
+	// NodeTableRow descendants, possibly grouped in <thead> and <tbody>
+	// elements, and its caption, if any, is a NodeParagraph child.
+	NodeTable
+	NodeTableRow
+	NodeTableCell
+
+	// NodeRawHTML is HTML that has no Markdown equivalent, such as a
+	// table with merged cells. Data holds it verbatim.
+	NodeRawHTML
+
+	// NodeCheckbox is a checkbox that starts a list item, which marks
+	// the item as a task. Other checkboxes are left out.
+	NodeCheckbox
 )
 
 const (
@@ -29,6 +59,25 @@ const (
 	NodeHeaderOrder = "head-order"
 	NodeImageSrc    = "img-src"
 	NodeImageAlt    = "img-alt"
+
+	// NodeRowHeader is set on the header row of a table, which is either
+	// in a <thead> or made of <th> cells only.
+	NodeRowHeader = "row-header"
+
+	// NodeCellAlign is the alignment of a table cell: "left", "center"
+	// or "right", if it has one.
+	NodeCellAlign = "cell-align"
+
+	// NodeListStart is the number of the first item of an ordered list,
+	// if it doesn't start at 1.
+	NodeListStart = "list-start"
+
+	// NodeCodeLanguage is the language of a preformatted block of code,
+	// if it is known.
+	NodeCodeLanguage = "code-lang"
+
+	// NodeChecked is set on a checkbox that is checked.
+	NodeChecked = "checked"
 )
 
 type Node struct {
@@ -50,11 +99,29 @@ func FromHTMLNode(n *html.Node) *Node {
 	}
 
 	switch n.Type {
+	case html.DocumentNode:
+		root.Kind = NodeBlock
 	case html.TextNode:
 		root.Kind = NodePlainText
 		root.Data = n.Data
 	case html.ElementNode:
 		switch n.Data {
+		case "html", "body", "div", "section", "article", "aside", "header", "footer", "main", "nav", "figure", "figcaption":
+			root.Kind = NodeBlock
+		case "p":
+			root.Kind = NodeParagraph
+		case "br":
+			root.Kind = NodeLineBreak
+		case "blockquote":
+			root.Kind = NodeBlockquote
+		case "cite":
+			// Elsewhere <cite> marks the title of a work, which is
+			// conventionally set in italics.
+			if n.Parent != nil && n.Parent.Type == html.ElementNode && n.Parent.Data == "blockquote" {
+				root.Kind = NodeCitation
+			} else {
+				root.Kind = NodeEmphasizedText
+			}
 		case "a":
 			root.Kind = NodeLink
 			for _, attr := range n.Attr {
@@ -73,17 +140,17 @@ func FromHTMLNode(n *html.Node) *Node {
 		case "s":
 			root.Kind = NodeStrikeText
 		case "pre":
-			for _, a := range n.Attr {
-				if a.Key == "class" && a.Val == "wp-block-code" {
-					return &Node{
-						Kind: NodePreformatted,
-						FirstChild: &Node{
-							Kind: NodePlainText,
-							Data: n.FirstChild.FirstChild.Data,
-						},
-					}
-				}
+			// Syntax highlighters wrap the code in <code> and <span>
+			// elements of their own, none of which matter to Markdown.
+			root.Kind = NodePreformatted
+			if lang := codeLanguage(n); lang != "" {
+				root.Attrs[NodeCodeLanguage] = lang
 			}
+			root.FirstChild = &Node{
+				Kind: NodePlainText,
+				Data: preformattedText(n),
+			}
+			return root
 		case "code":
 			root.Kind = NodeMonoText
 		case "h1":
@@ -105,11 +172,50 @@ func FromHTMLNode(n *html.Node) *Node {
 			root.Kind = NodeHeader
 			root.Attrs[NodeHeaderOrder] = "6"
 		case "ol":
+			// Markdown numbers lists upwards only
+			if hasAttr(n, "reversed") {
+				var b strings.Builder
+				if err := html.Render(&b, n); err == nil {
+					return &Node{Kind: NodeRawHTML, Data: b.String()}
+				}
+			}
 			root.Kind = NodeOrderedList
+			for _, attr := range n.Attr {
+				if attr.Key == "start" {
+					root.Attrs[NodeListStart] = strings.TrimSpace(attr.Val)
+				}
+			}
 		case "ul":
 			root.Kind = NodeUnorderedList
 		case "li":
 			root.Kind = NodeListItem
+		case "table":
+			if !isSimpleTable(n) {
+				var b strings.Builder
+				if err := html.Render(&b, n); err == nil {
+					return &Node{Kind: NodeRawHTML, Data: b.String()}
+				}
+			}
+			root.Kind = NodeTable
+		case "caption":
+			root.Kind = NodeParagraph
+		case "tr":
+			root.Kind = NodeTableRow
+			if isHeaderRow(n) {
+				root.Attrs[NodeRowHeader] = "true"
+			}
+		case "th", "td":
+			root.Kind = NodeTableCell
+			if align := cellAlign(n); align != "" {
+				root.Attrs[NodeCellAlign] = align
+			}
+		case "input":
+			if isTaskCheckbox(n) {
+				root.Kind = NodeCheckbox
+				if hasAttr(n, "checked") {
+					root.Attrs[NodeChecked] = "true"
+				}
+			}
 		case "img":
 			root.Kind = NodeImage
 			for _, attr := range n.Attr {
@@ -142,3 +248,200 @@ func FromHTMLNode(n *html.Node) *Node {
 
 	return root
 }
+
+// blockElements are the elements that can't be part of a Markdown table
+// cell.
+var blockElements = map[string]bool{
+	"address": true, "blockquote": true, "div": true, "dl": true,
+	"figure": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "hr": true, "ol": true, "p": true,
+	"pre": true, "table": true, "ul": true,
+}
+
+// isSimpleTable reports whether the table n can be written as a GitHub
+// Flavored Markdown table: none of its cells span several rows or columns
+// or hold block content.
+func isSimpleTable(n *html.Node) bool {
+	var simple func(n *html.Node, inCell bool) bool
+	simple = func(n *html.Node, inCell bool) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if inCell && blockElements[c.Data] {
+				return false
+			}
+			isCell := c.Data == "td" || c.Data == "th"
+			if isCell {
+				for _, a := range c.Attr {
+					if (a.Key == "colspan" || a.Key == "rowspan") && strings.TrimSpace(a.Val) != "1" {
+						return false
+					}
+				}
+			}
+			if !simple(c, inCell || isCell) {
+				return false
+			}
+		}
+		return true
+	}
+	return simple(n, false)
+}
+
+// isHeaderRow reports whether the table row n is a header row.
+func isHeaderRow(n *html.Node) bool {
+	if n.Parent != nil && n.Parent.Type == html.ElementNode && n.Parent.Data == "thead" {
+		return true
+	}
+
+	cells := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		if c.Data != "th" {
+			return false
+		}
+		cells++
+	}
+	return cells > 0
+}
+
+// cellAlign returns the alignment of the table cell n, which is given by
+// its align attribute, a text-align style or, in the block editor, a
+// has-text-align-* class.
+func cellAlign(n *html.Node) string {
+	for _, a := range n.Attr {
+		var v string
+		switch a.Key {
+		case "align":
+			v = a.Val
+		case "style":
+			for _, decl := range strings.Split(a.Val, ";") {
+				if p := strings.SplitN(decl, ":", 2); len(p) == 2 && strings.TrimSpace(p[0]) == "text-align" {
+					v = p[1]
+				}
+			}
+		case "class":
+			for _, class := range strings.Fields(a.Val) {
+				if strings.HasPrefix(class, "has-text-align-") {
+					v = strings.TrimPrefix(class, "has-text-align-")
+				}
+			}
+		}
+
+		switch v = strings.ToLower(strings.TrimSpace(v)); v {
+		case "left", "center", "right":
+			return v
+		}
+	}
+	return ""
+}
+
+// isTaskCheckbox reports whether the <input> element n is a checkbox that
+// starts a list item, which makes the item a task.
+func isTaskCheckbox(n *html.Node) bool {
+	if n.Parent == nil || n.Parent.Type != html.ElementNode || n.Parent.Data != "li" {
+		return false
+	}
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode || s.Type == html.TextNode && strings.TrimSpace(s.Data) != "" {
+			return false
+		}
+	}
+	for _, attr := range n.Attr {
+		if attr.Key == "type" && strings.EqualFold(attr.Val, "checkbox") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAttr reports whether n has the attribute key.
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// preformattedText returns the text of the <pre> element n as it is
+// displayed.
+func preformattedText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+			case c.Type == html.ElementNode && c.Data == "br":
+				b.WriteString("\n")
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// codeLanguage returns the language of the code in the <pre> element n, as
+// given on it or on a <code> element inside it by the block editor or a
+// syntax highlighting plugin:
+//
+//	<pre><code class="language-go">        block editor, Prismatic, Prism.js
+//	<pre class="brush: go; notranslate">   SyntaxHighlighter Evolved
+//	<pre data-enlighter-language="go">     Enlighter
+func codeLanguage(n *html.Node) string {
+	nodes := []*html.Node{n}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "code" {
+			nodes = append(nodes, c)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, a := range n.Attr {
+			var lang string
+			switch a.Key {
+			case "data-enlighter-language", "data-lang", "lang":
+				lang = a.Val
+			case "class":
+				for _, class := range strings.Fields(a.Val) {
+					for _, prefix := range []string{"language-", "lang-"} {
+						if strings.HasPrefix(class, prefix) {
+							lang = strings.TrimPrefix(class, prefix)
+						}
+					}
+				}
+				for _, opt := range strings.Split(a.Val, ";") {
+					if kv := strings.SplitN(opt, ":", 2); len(kv) == 2 && strings.TrimSpace(kv[0]) == "brush" {
+						lang = kv[1]
+					}
+				}
+			}
+
+			if lang = cleanLanguage(lang); lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// cleanLanguage returns lang if it can be written after a code fence, or ""
+// if it can't.
+func cleanLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	for _, r := range lang {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', strings.ContainsRune("+#._-", r):
+		default:
+			return ""
+		}
+	}
+	return lang
+}
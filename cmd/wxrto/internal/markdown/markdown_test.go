@@ -0,0 +1,221 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// parse converts the HTML document src.
+func parse(t *testing.T, src string) *Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parsing %q failed: %v", src, err)
+	}
+	return FromHTMLNode(doc)
+}
+
+// find returns the first node of kind k in n, depth first, or nil.
+func find(n *Node, k NodeKind) *Node {
+	if n.Kind == k {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if f := find(c, k); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestFromHTMLNodeKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		kind NodeKind
+		want bool
+	}{
+		{"div", `<div>a</div>`, NodeBlock, true},
+		{"figure", `<figure><img src="a.png"></figure>`, NodeBlock, true},
+		{"paragraph", `<p>a</p>`, NodeParagraph, true},
+		{"line break", `<p>a<br>b</p>`, NodeLineBreak, true},
+		{"blockquote", `<blockquote>a</blockquote>`, NodeBlockquote, true},
+		{"citation", `<blockquote><p>a</p><cite>b</cite></blockquote>`, NodeCitation, true},
+		{"cite outside a quote", `<p><cite>Dune</cite></p>`, NodeCitation, false},
+		{"cite outside a quote is emphasized", `<p><cite>Dune</cite></p>`, NodeEmphasizedText, true},
+		{"strike", `<p><s>a</s></p>`, NodeStrikeText, true},
+		{"code", `<p><code>a</code></p>`, NodeMonoText, true},
+		{"pre", `<pre>a</pre>`, NodePreformatted, true},
+		{"ordered list", `<ol><li>a</li></ol>`, NodeOrderedList, true},
+		{"reversed list", `<ol reversed><li>a</li></ol>`, NodeRawHTML, true},
+		{"table", `<table><tr><td>a</td></tr></table>`, NodeTable, true},
+		{"table row", `<table><tr><td>a</td></tr></table>`, NodeTableRow, true},
+		{"table cell", `<table><tr><td>a</td></tr></table>`, NodeTableCell, true},
+		{"caption", `<table><caption>a</caption><tr><td>b</td></tr></table>`, NodeParagraph, true},
+		{"merged cells", `<table><tr><td colspan="2">a</td></tr></table>`, NodeRawHTML, true},
+		{"task checkbox", `<ul><li><input type="checkbox"> a</li></ul>`, NodeCheckbox, true},
+		{"task checkbox after space", `<ul><li> <input type="checkbox"> a</li></ul>`, NodeCheckbox, true},
+		{"checkbox after text", `<ul><li>a <input type="checkbox"></li></ul>`, NodeCheckbox, false},
+		{"checkbox after an element", `<ul><li><b>a</b><input type="checkbox"></li></ul>`, NodeCheckbox, false},
+		{"checkbox outside a list", `<p><input type="checkbox"> a</p>`, NodeCheckbox, false},
+		{"text input", `<ul><li><input type="text"> a</li></ul>`, NodeCheckbox, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := find(parse(t, tt.src), tt.kind) != nil; got != tt.want {
+				t.Errorf("%q has a node of kind %d: %v, want %v", tt.src, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHTMLNodeAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		kind NodeKind
+		attr string
+		want string
+	}{
+		{"link", `<a href="/about/">a</a>`, NodeLink, NodeAttrHref, "/about/"},
+		{"header", `<h3>a</h3>`, NodeHeader, NodeHeaderOrder, "3"},
+		{"image", `<img src="a.png">`, NodeImage, NodeImageSrc, "a.png"},
+		{"list start", `<ol start=" 4 "><li>a</li></ol>`, NodeOrderedList, NodeListStart, "4"},
+		{"checked", `<ul><li><input type="checkbox" checked> a</li></ul>`, NodeCheckbox, NodeChecked, "true"},
+		{"unchecked", `<ul><li><input type="checkbox"> a</li></ul>`, NodeCheckbox, NodeChecked, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := find(parse(t, tt.src), tt.kind)
+			if n == nil {
+				t.Fatalf("%q has no node of kind %d", tt.src, tt.kind)
+			}
+			if got := n.Attrs[tt.attr]; got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.attr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHTMLNodeCode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		lang string
+		text string
+	}{
+		{"plain", `<pre>x := 1</pre>`, "", "x := 1"},
+		{"empty block", `<pre class="wp-block-code"></pre>`, "", ""},
+		{"empty code", `<pre class="wp-block-code"><code></code></pre>`, "", ""},
+		{"block editor", `<pre class="wp-block-code"><code class="language-go">x := 1</code></pre>`, "go", "x := 1"},
+		{"lang class", `<pre><code class="hljs lang-Python">pass</code></pre>`, "python", "pass"},
+		{"syntaxhighlighter", `<pre class="brush: go; notranslate">x := 1</pre>`, "go", "x := 1"},
+		{"enlighter", `<pre class="EnlighterJSRAW" data-enlighter-language="go">x := 1</pre>`, "go", "x := 1"},
+		{"invalid language", `<pre data-lang="go lang">x</pre>`, "", "x"},
+		{"highlighted", `<pre><code class="language-go"><span class="k">func</span> f() {<br>}</code></pre>`, "go", "func f() {\n}"},
+		{"entities", `<pre>a &lt; b &amp;&amp; c</pre>`, "", "a < b && c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := find(parse(t, tt.src), NodePreformatted)
+			if n == nil {
+				t.Fatalf("%q has no preformatted node", tt.src)
+			}
+			if got := n.Attrs[NodeCodeLanguage]; got != tt.lang {
+				t.Errorf("language = %q, want %q", got, tt.lang)
+			}
+			if n.FirstChild == nil || n.FirstChild.Kind != NodePlainText || n.FirstChild.NextSibling != nil {
+				t.Fatalf("children = %+v, want a single text node", n.FirstChild)
+			}
+			if got := n.FirstChild.Data; got != tt.text {
+				t.Errorf("text = %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+// findElement returns the first <tag> element in n, depth first, or nil.
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if f := findElement(c, tag); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestIsSimpleTable(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"plain", `<table><tr><td>a</td><td>b</td></tr></table>`, true},
+		{"inline content", `<table><tr><td><b>a</b> <a href="/">b</a><br>c</td></tr></table>`, true},
+		{"caption", `<table><caption><p>a</p></caption><tr><td>b</td></tr></table>`, true},
+		{"span of one", `<table><tr><td colspan=" 1 " rowspan="1">a</td></tr></table>`, true},
+		{"colspan", `<table><tr><td colspan="2">a</td></tr><tr><td>b</td><td>c</td></tr></table>`, false},
+		{"rowspan", `<table><tr><th rowspan="2">a</th><td>b</td></tr><tr><td>c</td></tr></table>`, false},
+		{"paragraph in cell", `<table><tr><td><p>a</p></td></tr></table>`, false},
+		{"list in cell", `<table><tr><td><span><ul><li>a</li></ul></span></td></tr></table>`, false},
+		{"nested table", `<table><tr><td><table><tr><td>a</td></tr></table></td></tr></table>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("parsing %q failed: %v", tt.src, err)
+			}
+			if got := isSimpleTable(findElement(doc, "table")); got != tt.want {
+				t.Errorf("isSimpleTable(%q) = %v, want %v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHTMLNodeTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		header bool
+		align  []string
+	}{
+		{"thead", `<table><thead><tr><td>a</td><td>b</td></tr></thead><tbody><tr><td>c</td><td>d</td></tr></tbody></table>`, true, []string{"", ""}},
+		{"th row", `<table><tr><th>a</th><th>b</th></tr><tr><td>c</td><td>d</td></tr></table>`, true, []string{"", ""}},
+		{"th column", `<table><tr><th>a</th><td>b</td></tr></table>`, false, []string{"", ""}},
+		{"no header", `<table><tr><td>a</td><td>b</td></tr></table>`, false, []string{"", ""}},
+		{"align", `<table><tr><td align="CENTER">a</td><td align="justify">b</td></tr></table>`, false, []string{"center", ""}},
+		{"style", `<table><tr><td style="color: red; text-align: right">a</td><td style="text-align:left">b</td></tr></table>`, false, []string{"right", "left"}},
+		{"block editor", `<table><tr><td class="has-text-align-center" data-align="center">a</td><td class="has-text-align-right">b</td></tr></table>`, false, []string{"center", "right"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := find(parse(t, tt.src), NodeTableRow)
+			if row == nil {
+				t.Fatalf("%q has no table row", tt.src)
+			}
+			if got := row.Attrs[NodeRowHeader] != ""; got != tt.header {
+				t.Errorf("first row is a header: %v, want %v", got, tt.header)
+			}
+			var align []string
+			for c := row.FirstChild; c != nil; c = c.NextSibling {
+				if c.Kind == NodeTableCell {
+					align = append(align, c.Attrs[NodeCellAlign])
+				}
+			}
+			if strings.Join(align, ",") != strings.Join(tt.align, ",") {
+				t.Errorf("alignment = %q, want %q", align, tt.align)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// rewriteLinks replaces the links and image sources in doc that point into
+// the exported blog with paths relative to the root of the new site, so
+// that they keep working once it is served from another address.
+func rewriteLinks(channel *wxr.Channel, doc *html.Node) {
+	if doc.Type == html.ElementNode {
+		var attr string
+		switch doc.Data {
+		case "a":
+			attr = "href"
+		case "img":
+			attr = "src"
+		}
+		for i, a := range doc.Attr {
+			if a.Key != attr || a.Namespace != "" {
+				continue
+			}
+			if p, ok := channel.BlogPath(a.Val); ok {
+				doc.Attr[i].Val = p
+			}
+		}
+	}
+
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		rewriteLinks(channel, c)
+	}
+}
+
+// aliases returns the paths item was reachable under on the old blog, for
+// the generator to redirect to its new location. Links rewritten by
+// rewriteLinks point at these paths. A path that item keeps on the new
+// site needs no redirect.
+func aliases(channel *wxr.Channel, item wxr.Item) []string {
+	p, ok := channel.BlogPath(item.Link)
+	// Redirects can't be keyed on a query, e.g. "/?p=9"
+	if !ok || p == "/" || p[1] == '?' || p[1] == '#' {
+		return nil
+	}
+	if u, ok := contentURL(item); ok && strings.TrimSuffix(u, "/") == strings.TrimSuffix(p, "/") {
+		return nil
+	}
+	return []string{p}
+}
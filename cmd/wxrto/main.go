@@ -3,13 +3,13 @@
 package main
 
 import (
-	"bytes"
-	"encoding/xml"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,6 +30,31 @@ var (
 	// generator is the name of a static site generator to convert to.
 	generator *string
 
+	// emitComments controls whether each post's approved comments are
+	// written to a data file alongside the converted content.
+	emitComments *bool
+
+	// termPaths controls whether terms of hierarchical taxonomies, such as
+	// categories, are written to the front matter as their full path.
+	termPaths *bool
+
+	// emitGUID controls whether each item's WordPress GUID is written to
+	// its front matter, e.g. to build redirects from the old site.
+	emitGUID *bool
+
+	// rewriteInternalLinks controls whether links into the old blog are
+	// rewritten to paths on the new site, with the old addresses of posts
+	// and pages kept as aliases.
+	rewriteInternalLinks *bool
+
+	// splitBytes and splitItems limit the size of each file written by
+	// the split command, and splitByType and splitByYear give each post
+	// type or year files of their own.
+	splitBytes  *int
+	splitItems  *int
+	splitByType *bool
+	splitByYear *bool
+
 	// outputDir is the root directory to where markdown files and assets
 	// will be saved to.
 	outputDir *string
@@ -45,68 +70,131 @@ var (
 	// pagesDir is the directory relative to the contentDir where standalone static
 	// Markdown pages will be written to.
 	pagesDir = contentDir
+
+	// commentsDir is the directory relative to outputDir where per-post
+	// comment data files will be written to.
+	commentsDir = func(out string) string { return fmt.Sprintf("%s/data/comments", out) }
+
+	// configDir is the directory relative to outputDir where generated
+	// site configuration, such as menus, will be written to.
+	configDir = func(out string) string { return fmt.Sprintf("%s/config/_default", out) }
 )
 
 func init() {
 	inputFile = flag.String("input", "", "the WordPress WXR file to convert (if not provided, stdin will be used)")
 	generator = flag.String("generator", "hugo", "static site generator output format")
 	outputDir = flag.String("outdir", "output", "directory to save converted files and assets")
-	flag.Parse()
+	emitComments = flag.Bool("comments", false, "write each post's approved comments to a data file")
+	termPaths = flag.Bool("term-paths", false, "write categories and other hierarchical terms as their full path, e.g. News/Local")
+	emitGUID = flag.Bool("guid", false, "write each item's WordPress GUID to its front matter")
+	rewriteInternalLinks = flag.Bool("rewrite-links", false, "rewrite links to the old blog as site-relative paths and keep the old addresses of posts and pages as aliases")
+	splitBytes = flag.Int("max-bytes", 0, "split: the maximum size of each file in bytes")
+	splitItems = flag.Int("max-items", 0, "split: the maximum number of items in each file")
+	splitByType = flag.Bool("by-type", false, "split: write the items of each post type to files of their own")
+	splitByYear = flag.Bool("by-year", false, "split: write the items of each year to files of their own")
 }
 
 func main() {
-	var in io.Reader
+	flag.Parse()
+	switch cmd := flag.Arg(0); cmd {
+	case "":
+		convert(openInput())
+	case "validate":
+		// Accept flags after the command as well as before it
+		flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(validate(openInput()))
+	case "split":
+		flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(split(openInput()))
+	case "merge":
+		flag.CommandLine.Parse(flag.Args()[1:])
+		os.Exit(merge(os.Stdout, flag.Args()))
+	default:
+		log.Fatalf("unknown command %q", cmd)
+	}
+}
 
+// openInput returns the WXR file named by -input, or stdin if there isn't
+// one.
+func openInput() io.Reader {
 	// Input filepath wasn't provided, fall back to stdin
 	if *inputFile == "" {
-		in = os.Stdin
-	} else {
-		f, err := os.Open(*inputFile)
-		if err != nil {
-			log.Fatal(err)
-		}
-		in = f
+		return os.Stdin
 	}
 
-	// xml.Unmarshal wants to operate on a []byte
-	buf := bytes.Buffer{}
-	_, err := io.Copy(&buf, in)
+	f, err := os.Open(*inputFile)
 	if err != nil {
 		log.Fatal(err)
 	}
+	return f
+}
 
-	// Deserialize it into XML
-	var rss wxr.RSS
-	err = xml.Unmarshal(buf.Bytes(), &rss)
-	if err != nil {
-		log.Fatal(err)
-	}
+// convert writes every post and page in the WXR file to the output
+// directory.
+func convert(in io.Reader) {
+	// Exports from real sites are often damaged in small ways, so recover
+	// what can be recovered rather than giving up on the whole site.
+	dec := wxr.NewDecoder(in)
+	dec.Lenient = true
 
 	var wg sync.WaitGroup
 
-	for _, item := range rss.Channel.Items {
+	// Bound the number of items in flight so that memory use doesn't grow
+	// with the size of the export.
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	// Menus link to items from all over the export, so keep just enough
+	// of every item to resolve them once the whole export has been read.
+	var menuItems []wxr.Item
+
+	// The channel's terms all precede its items, so the taxonomies are
+	// complete by the time the first item has been read.
+	var taxonomies map[string]*wxr.Taxonomy
+
+	for dec.Next() {
+		if taxonomies == nil {
+			taxonomies = dec.Channel().Taxonomies()
+		}
+
+		menuItems = appendMenuItem(menuItems, dec.Item())
+
 		// The processItem goroutine will release the lock before returning
 		wg.Add(1)
-		go func(r *wxr.RSS, i wxr.Item) {
+		sem <- struct{}{}
+		go func(c *wxr.Channel, i wxr.Item) {
 			defer wg.Done()
-			processItem(r, i)
-		}(&rss, item)
+			defer func() { <-sem }()
+			processItem(c, taxonomies, i)
+		}(dec.Channel(), dec.Item())
 	}
 
 	// Wait for any dispatched goroutines to finish up before exiting
 	wg.Wait()
+
+	for _, w := range dec.Warnings() {
+		log.Print(w)
+	}
+
+	if err := dec.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	channel := *dec.Channel()
+	channel.Items = menuItems
+	taxonomies = channel.Taxonomies()
+	writeMenus(wxr.Menus(&channel), taxonomies)
+	writeTermPages(taxonomies)
 }
 
 // processItem converts a WordPress blog post or static page into a Markdown
 // file that is compatible with the selected generator.
-func processItem(rss *wxr.RSS, item wxr.Item) {
-	postType := stripCharData(item.PostType)
+func processItem(channel *wxr.Channel, taxonomies map[string]*wxr.Taxonomy, item wxr.Item) {
+	postType := item.PostType
 	if postType != "post" && postType != "page" {
 		return
 	}
 
-	status := stripCharData(item.Status)
-	if status == "trash" {
+	if item.Status() == wxr.StatusTrash {
 		log.Printf("%q marked as trash, ignoring", item.Title)
 		return
 	}
@@ -123,26 +211,18 @@ func processItem(rss *wxr.RSS, item wxr.Item) {
 		return
 	}
 
-	// Parse the date so we can prefix the post with YYYY-MM-DD.
+	// The date is used to prefix the post with YYYY-MM-DD.
 	//
 	// TODO: make this more configurable, not everyone wants the date
 	// in the filename.
-	posted, err := time.Parse("2006-01-02 15:04:05", stripCharData(item.PostDate))
-	if err != nil {
-		log.Printf("failed to parse post date: %v", err)
+	posted := item.PublishedAt()
+	if posted.IsZero() {
+		log.Printf("%q has no valid post date, ignoring", item.Title)
 		return
 	}
 
-	// TODO: probably write a function to kebab-case the title, as I'm
-	// not sure WP guarantees this will be the way I think it is
-	name := stripCharData(item.PostName)
-
-	var filename string
-	if postType == "post" {
-		filename = fmt.Sprintf("%s/%s-%s.md", path, posted.Format("2006-01-02"), name)
-	} else {
-		filename = fmt.Sprintf("%s/%s.md", path, name)
-	}
+	basename := contentName(item, posted)
+	filename := fmt.Sprintf("%s/%s.md", path, basename)
 
 	file, err := os.Create(filename)
 	if err != nil {
@@ -170,30 +250,135 @@ func processItem(rss *wxr.RSS, item wxr.Item) {
 	// TODO: This will have to be configurable to support more than just
 	// Hugo
 	var frontmatter struct {
-		Title string
-		Date  string
-		Draft bool
+		Title      string
+		Date       string
+		Draft      bool
+		GUID       string
+		Aliases    []string
+		Categories []string
+		Tags       []string
+
+		// Taxonomies holds the item's terms in custom taxonomies, keyed
+		// by taxonomy name.
+		Taxonomies map[string][]string
 	}
 
 	frontmatter.Title = `"` + item.Title + `"`
-	frontmatter.Date = posted.Format("2006-01-02 15:04:05")
-	if item.Status == "publish" {
-		frontmatter.Draft = false
-	} else {
-		frontmatter.Draft = true
+	frontmatter.Date = posted.Format(wxr.DateLayout)
+	frontmatter.Draft = item.Status() != wxr.StatusPublish
+	if *emitGUID {
+		frontmatter.GUID = strings.TrimSpace(item.GUID.Value)
+	}
+	if *rewriteInternalLinks {
+		frontmatter.Aliases = aliases(channel, item)
+		rewriteLinks(channel, htmlDoc)
+	}
+	for _, c := range item.Category {
+		name := termName(taxonomies, c)
+		switch c.Domain {
+		case wxr.TaxonomyCategory:
+			frontmatter.Categories = append(frontmatter.Categories, name)
+		case wxr.TaxonomyTag:
+			frontmatter.Tags = append(frontmatter.Tags, name)
+		case wxr.TaxonomyNavMenu, "":
+		default:
+			if frontmatter.Taxonomies == nil {
+				frontmatter.Taxonomies = make(map[string][]string)
+			}
+			frontmatter.Taxonomies[c.Domain] = append(frontmatter.Taxonomies[c.Domain], name)
+		}
 	}
 
 	t := template.Must(template.New(*generator + "-post").Parse(tmpl))
 
 	mdNode := markdown.FromHTMLNode(htmlDoc)
 	markdown := visitMarkdown(mdNode)
+	if markdown != "" {
+		markdown += "\n"
+	}
 
 	// Write the frontmatter, then the Markdown
 	t.Execute(file, frontmatter)
 	io.Copy(file, strings.NewReader(markdown))
 	log.Printf("%q => %q", item.Title, filename)
+
+	if *emitComments {
+		writeComments(basename, item)
+	}
+}
+
+// termName returns how the term c is named in the front matter. With
+// -term-paths, a term that has ancestors is named by its path from the
+// root of its taxonomy.
+func termName(taxonomies map[string]*wxr.Taxonomy, c wxr.ItemCategory) string {
+	if !*termPaths {
+		return c.Name
+	}
+
+	tx, ok := taxonomies[c.Domain]
+	if !ok {
+		return c.Name
+	}
+	t, ok := tx.Term(c.NiceName)
+	if !ok {
+		return c.Name
+	}
+	return termPath(t)
+}
+
+// contentName returns the name, without an extension, of the Markdown file
+// that item is converted to. Posts are prefixed with the date they were
+// published.
+func contentName(item wxr.Item, posted time.Time) string {
+	// TODO: probably write a function to kebab-case the title, as I'm
+	// not sure WP guarantees this will be the way I think it is
+	name := item.PostName
+
+	if item.PostType == "post" {
+		return fmt.Sprintf("%s-%s", posted.Format("2006-01-02"), name)
+	}
+	return name
+}
+
+// contentURL returns the path item is served at on the converted site, if
+// it is converted.
+func contentURL(item wxr.Item) (string, bool) {
+	if item.PostType != "post" && item.PostType != "page" {
+		return "", false
+	}
+	posted := item.PublishedAt()
+	if posted.IsZero() {
+		return "", false
+	}
+
+	name := contentName(item, posted)
+	if item.PostType == "post" {
+		return "/posts/" + name + "/", true
+	}
+	return "/" + name + "/", true
 }
 
+const (
+	// lineBreak is a hard line break within a Markdown paragraph.
+	lineBreak = "  \n"
+
+	// listSeparator keeps two lists of the same kind in a row from being
+	// read as one.
+	listSeparator = "<!-- -->"
+)
+
+var (
+	// blankLine separates the paragraphs of text that isn't wrapped in
+	// block elements, as WordPress's wpautop does.
+	blankLine = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+	// spaceRun matches whitespace that HTML collapses into a single space.
+	spaceRun = regexp.MustCompile(`\s+`)
+
+	// spacedBreak matches a hard line break and the whitespace around it.
+	spacedBreak = regexp.MustCompile(`\s*  \n\s*`)
+)
+
 func visitMarkdown(node *markdown.Node) string {
 	visitChildren := func(n *markdown.Node) string {
 		if n == nil {
@@ -207,13 +392,32 @@ func visitMarkdown(node *markdown.Node) string {
 		return b.String()
 	}
 
+	// visitInline renders the children of n as the content of a single
+	// block.
+	visitInline := func(n *markdown.Node) string {
+		return strings.TrimSpace(spacedBreak.ReplaceAllString(visitChildren(n), lineBreak))
+	}
+
 	if node == nil {
 		return ""
 	}
 
 	switch node.Kind {
+	case markdown.NodeBlock:
+		return visitBlocks(node)
+	case markdown.NodeParagraph:
+		return visitInline(node)
+	case markdown.NodeBlockquote:
+		return quote(visitBlocks(node))
+	case markdown.NodeCitation:
+		if s := visitInline(node); s != "" {
+			return "— " + s
+		}
+		return ""
 	case markdown.NodePlainText:
-		return node.Data
+		return spaceRun.ReplaceAllString(node.Data, " ")
+	case markdown.NodeLineBreak:
+		return lineBreak
 	case markdown.NodeStrongText:
 		return fmt.Sprintf("**%s**", visitChildren(node))
 	case markdown.NodeEmphasizedText:
@@ -230,31 +434,328 @@ func visitMarkdown(node *markdown.Node) string {
 		if err != nil {
 			level = 1
 		}
-		return fmt.Sprintf("%s %s", "######"[:level], visitChildren(node))
+		// An ATX heading ends at the end of its line
+		text := strings.ReplaceAll(visitInline(node), lineBreak, " ")
+		return fmt.Sprintf("%s %s", "######"[:level], text)
 	case markdown.NodeImage:
 		return fmt.Sprintf("![%s](%s)", node.Attrs[markdown.NodeImageAlt], node.Attrs[markdown.NodeImageSrc])
+	case markdown.NodeTable:
+		return visitTable(node)
+	case markdown.NodeRawHTML:
+		return strings.TrimSpace(node.Data)
 	case markdown.NodePreformatted:
-		return fmt.Sprintf("```%s```", visitChildren(node))
-	case markdown.NodeUnorderedList:
-		var s []string
-		for c := node.FirstChild; c != nil; c = c.NextSibling {
-			s = append(s, fmt.Sprintf("* %s", visitChildren(c)))
-		}
-		return strings.Join(s, "\n")
-	case markdown.NodeOrderedList:
-		var s []string
-		i := 1
-		for c := node.FirstChild; c != nil; c = c.NextSibling {
-			s = append(s, fmt.Sprintf("%d. %s", i, visitChildren(c)))
-			i += 1
+		return visitCode(node)
+	case markdown.NodeUnorderedList, markdown.NodeOrderedList:
+		return visitList(node)
+	case markdown.NodeCheckbox:
+		// The text of the item usually follows after a space
+		if node.Attrs[markdown.NodeChecked] != "" {
+			return "[x]"
 		}
-		return strings.Join(s, "\n")
+		return "[ ]"
 	default:
 		return visitChildren(node)
 	}
 }
 
-// TODO: Fixup the wxr/xml package to parse out the chardata
-func stripCharData(s string) string {
-	return strings.TrimSuffix(strings.TrimPrefix(s, "<![CDATA["), "]]>")
+// isBlock reports whether nodes of kind k are laid out as blocks of their
+// own.
+func isBlock(k markdown.NodeKind) bool {
+	switch k {
+	case markdown.NodeBlock,
+		markdown.NodeParagraph,
+		markdown.NodeBlockquote,
+		markdown.NodeCitation,
+		markdown.NodeTable,
+		markdown.NodeRawHTML,
+		markdown.NodeHeader,
+		markdown.NodePreformatted,
+		markdown.NodeUnorderedList,
+		markdown.NodeOrderedList:
+		return true
+	}
+	return false
+}
+
+// visitBlocks renders the children of a block container as Markdown blocks
+// separated by blank lines. Inline content between the blocks is split
+// into paragraphs the way WordPress's wpautop splits post content: at
+// blank lines, with the remaining newlines becoming hard line breaks.
+func visitBlocks(node *markdown.Node) string {
+	return strings.Join(blocksOf(node), "\n\n")
+}
+
+// blocksOf renders the children of a block container as a list of
+// Markdown blocks, see visitBlocks.
+func blocksOf(node *markdown.Node) []string {
+	var blocks []string
+	var run string
+
+	// last is the kind of the last block if it was a list, which a list
+	// of the same kind right after it would continue.
+	var last markdown.NodeKind
+
+	flush := func() {
+		s := strings.TrimSpace(spacedBreak.ReplaceAllString(run, lineBreak))
+		if s != "" {
+			blocks = append(blocks, s)
+			last = markdown.NodeUnknown
+		}
+		run = ""
+	}
+
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case isBlock(c.Kind):
+			flush()
+			if s := visitMarkdown(c); s != "" {
+				if c.Kind == last {
+					blocks = append(blocks, listSeparator)
+				}
+				blocks = append(blocks, s)
+				last = markdown.NodeUnknown
+				if c.Kind == markdown.NodeUnorderedList || c.Kind == markdown.NodeOrderedList {
+					last = c.Kind
+				}
+			}
+		case c.Kind == markdown.NodePlainText:
+			for i, para := range blankLine.Split(c.Data, -1) {
+				if i > 0 {
+					flush()
+				}
+				for j, line := range strings.Split(para, "\n") {
+					if j > 0 && strings.TrimSpace(run) != "" {
+						run += lineBreak
+					}
+					run += spaceRun.ReplaceAllString(line, " ")
+				}
+			}
+		default:
+			run += visitMarkdown(c)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// visitList renders an ordered or unordered list. The content of each item
+// is indented to line up with the text after its marker, which keeps
+// nested lists and paragraphs inside the item.
+//
+// The list is loose, with blank lines between its items, if any item holds
+// paragraphs or other blocks besides nested lists.
+func visitList(node *markdown.Node) string {
+	var items [][]string
+	loose := false
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Kind != markdown.NodeListItem {
+			continue
+		}
+		blocks := blocksOf(c)
+		items = append(items, blocks)
+
+		lists := 0
+		for b := c.FirstChild; b != nil; b = b.NextSibling {
+			switch {
+			case b.Kind == markdown.NodeUnorderedList || b.Kind == markdown.NodeOrderedList:
+				lists++
+			case isBlock(b.Kind):
+				loose = true
+			}
+		}
+		// Text split into paragraphs, see blocksOf
+		n := 0
+		for _, b := range blocks {
+			if b != listSeparator {
+				n++
+			}
+		}
+		if n > lists+1 {
+			loose = true
+		}
+	}
+
+	sep := "\n"
+	if loose {
+		sep = "\n\n"
+	}
+
+	n, err := strconv.Atoi(node.Attrs[markdown.NodeListStart])
+	if err != nil || n < 0 {
+		n = 1
+	}
+
+	var s []string
+	for _, blocks := range items {
+		marker := "* "
+		if node.Kind == markdown.NodeOrderedList {
+			marker = fmt.Sprintf("%d. ", n)
+			n++
+		}
+		content := indent(strings.Join(blocks, sep), len(marker))
+		s = append(s, strings.TrimRight(marker+content, " "))
+	}
+	return strings.Join(s, sep)
+}
+
+// indent indents every line of s but the first by n spaces. Blank lines
+// are left empty.
+func indent(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = strings.Repeat(" ", n) + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// visitTable renders a table as a GitHub Flavored Markdown table, followed
+// by its caption. Markdown tables must have a header row, so one with
+// empty cells is made up for tables that don't.
+func visitTable(node *markdown.Node) string {
+	var rows [][]*markdown.Node
+	var captions []string
+	header := false
+
+	var walk func(n *markdown.Node)
+	walk = func(n *markdown.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Kind {
+			case markdown.NodeTableRow:
+				var cells []*markdown.Node
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Kind == markdown.NodeTableCell {
+						cells = append(cells, cell)
+					}
+				}
+				if len(rows) == 0 && c.Attrs[markdown.NodeRowHeader] != "" {
+					header = true
+				}
+				rows = append(rows, cells)
+			case markdown.NodeParagraph:
+				if s := visitMarkdown(c); s != "" {
+					captions = append(captions, s)
+				}
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(node)
+
+	columns := 0
+	for _, r := range rows {
+		if len(r) > columns {
+			columns = len(r)
+		}
+	}
+	if columns == 0 {
+		return strings.Join(captions, "\n\n")
+	}
+
+	row := func(cells []*markdown.Node) string {
+		s := make([]string, columns)
+		for i, cell := range cells {
+			text := strings.ReplaceAll(visitMarkdown(cell), lineBreak, "<br>")
+			text = strings.TrimSpace(spaceRun.ReplaceAllString(text, " "))
+			s[i] = strings.ReplaceAll(text, "|", `\|`)
+		}
+		return "| " + strings.Join(s, " | ") + " |"
+	}
+
+	// Columns are aligned as the cells of the first row are
+	first := rows[0]
+
+	var lines []string
+	if header {
+		lines = append(lines, row(rows[0]))
+		rows = rows[1:]
+	} else {
+		lines = append(lines, row(nil))
+	}
+
+	delims := make([]string, columns)
+	for i := range delims {
+		delims[i] = "---"
+		if i < len(first) {
+			switch first[i].Attrs[markdown.NodeCellAlign] {
+			case "left":
+				delims[i] = ":---"
+			case "center":
+				delims[i] = ":---:"
+			case "right":
+				delims[i] = "---:"
+			}
+		}
+	}
+	lines = append(lines, "| "+strings.Join(delims, " | ")+" |")
+
+	for _, r := range rows {
+		lines = append(lines, row(r))
+	}
+
+	return strings.Join(append([]string{strings.Join(lines, "\n")}, captions...), "\n\n")
+}
+
+// visitCode renders a preformatted block as a fenced code block. The fence
+// is made longer than any run of backticks in the code, so that the code
+// can't end the block early.
+func visitCode(node *markdown.Node) string {
+	code := strings.TrimRight(textContent(node), "\n")
+
+	longest, run := 0, 0
+	for _, r := range code {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		if run > longest {
+			longest = run
+		}
+	}
+	fence := "```"
+	if longest >= len(fence) {
+		fence = strings.Repeat("`", longest+1)
+	}
+
+	open := fence + node.Attrs[markdown.NodeCodeLanguage]
+	if code == "" {
+		return open + "\n" + fence
+	}
+	return open + "\n" + code + "\n" + fence
+}
+
+// quote prefixes every line of the Markdown s with "> ", which nests when
+// s is itself a quotation.
+func quote(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textContent returns the text of node and its descendants as it is,
+// without any Markdown formatting.
+func textContent(node *markdown.Node) string {
+	if node.Kind == markdown.NodePlainText {
+		return node.Data
+	}
+
+	var b strings.Builder
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
 }
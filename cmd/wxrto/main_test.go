@@ -0,0 +1,704 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+
+	"github.com/connorkuehl/wxr"
+	"github.com/connorkuehl/wxr/cmd/wxrto/internal/markdown"
+)
+
+// render converts the post content src to Markdown.
+func render(t *testing.T, src string) string {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parsing %q failed: %v", src, err)
+	}
+	return visitMarkdown(markdown.FromHTMLNode(doc))
+}
+
+func TestVisitBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"paragraphs", `<p>a</p><p>b</p>`, "a\n\nb"},
+		{"empty paragraph", `<p>a</p><p> </p><p>b</p>`, "a\n\nb"},
+		{"whitespace collapsed", "<p>  a \n\t b  </p>", "a b"},
+		{"hard break", `<p>a<br>b</p>`, "a  \nb"},
+		{"hard break with spaces", "<p>a <br/>\n b</p>", "a  \nb"},
+		{"trailing break", `<p>a<br></p>`, "a"},
+		{"inline formatting", `<p><b>a</b> <i>b</i> <s>c</s></p>`, "**a** *b* ~~c~~"},
+		{"heading", `<h2>a</h2><p>b</p>`, "## a\n\nb"},
+		{"break in heading", "<h2>a<br>b\nc</h2>", "## a b c"},
+		{"div", `<div><p>a</p></div><div>b</div>`, "a\n\nb"},
+		{"bare text", "a\n\nb", "a\n\nb"},
+		{"bare text blank line with spaces", "a\n \t\n\nb", "a\n\nb"},
+		{"bare text newline", "a\nb", "a  \nb"},
+		{"bare text with inline elements", "<b>a</b> b\nc <i>d</i>\n\ne", "**a** b  \nc *d*\n\ne"},
+		{"bare text around blocks", "a\n<h2>b</h2>\nc", "a\n\n## b\n\nc"},
+		{"leading newline", "\na", "a"},
+		{"classic editor post", "Hello.\n\n<!--more-->\n\nThis is <a href=\"/\">a link</a>\nand more.", "Hello.\n\nThis is [a link](/)  \nand more."},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.src); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", `<blockquote>a</blockquote>`, "> a"},
+		{"paragraphs", `<blockquote><p>a</p><p>b</p></blockquote>`, "> a\n>\n> b"},
+		{"hard break", `<blockquote><p>a<br>b</p></blockquote>`, "> a  \n> b"},
+		{"citation", `<blockquote class="wp-block-quote"><p>a</p><cite>Ann</cite></blockquote>`, "> a\n>\n> — Ann"},
+		{"empty citation", `<blockquote><p>a</p><cite> </cite></blockquote>`, "> a"},
+		{"formatted citation", `<blockquote><p>a</p><cite><a href="/ann/">Ann</a></cite></blockquote>`, "> a\n>\n> — [Ann](/ann/)"},
+		{"pull quote", `<figure class="wp-block-pullquote"><blockquote><p>a</p><cite>Ann</cite></blockquote></figure>`, "> a\n>\n> — Ann"},
+		{"nested", `<blockquote><p>a</p><blockquote><p>b</p><p>c</p></blockquote></blockquote>`, "> a\n>\n> > b\n> >\n> > c"},
+		{"nested citations", `<blockquote><blockquote><p>a</p><cite>Ann</cite></blockquote><cite>Bob</cite></blockquote>`, "> > a\n> >\n> > — Ann\n>\n> — Bob"},
+		{"list", `<blockquote><ul><li>a</li><li>b</li></ul></blockquote>`, "> * a\n> * b"},
+		{"code", "<blockquote><pre>a\n\nb</pre></blockquote>", "> ```\n> a\n>\n> b\n> ```"},
+		{"empty", `<blockquote> </blockquote>`, ""},
+		{"between paragraphs", `<p>a</p><blockquote>b</blockquote><p>c</p>`, "a\n\n> b\n\nc"},
+		{"cite outside a quote", `<p>Read <cite>Dune</cite>.</p>`, "Read *Dune*."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.src); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisitTable(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"thead",
+			`<table><thead><tr><th>Name</th><th>Age</th></tr></thead><tbody><tr><td>Ann</td><td>7</td></tr></tbody></table>`,
+			"| Name | Age |\n| --- | --- |\n| Ann | 7 |",
+		},
+		{
+			"th row",
+			`<table><tr><th>Name</th><th>Age</th></tr><tr><td>Ann</td><td>7</td></tr></table>`,
+			"| Name | Age |\n| --- | --- |\n| Ann | 7 |",
+		},
+		{
+			"th in a later row",
+			`<table><tr><td>Ann</td><td>7</td></tr><tr><th>Bob</th><th>8</th></tr></table>`,
+			"|  |  |\n| --- | --- |\n| Ann | 7 |\n| Bob | 8 |",
+		},
+		{
+			"made up header",
+			`<table><tr><td>Ann</td><td>7</td></tr></table>`,
+			"|  |  |\n| --- | --- |\n| Ann | 7 |",
+		},
+		{
+			"ragged rows",
+			`<table><tr><th>a</th></tr><tr><td>b</td><td>c</td></tr></table>`,
+			"| a |  |\n| --- | --- |\n| b | c |",
+		},
+		{
+			"align",
+			`<table><tr><th align="left">a</th><th align="center">b</th><th align="right">c</th><th>d</th></tr></table>`,
+			"| a | b | c | d |\n| :--- | :---: | ---: | --- |",
+		},
+		{
+			"style",
+			`<table><tr><td style="text-align: right">1</td><td>2</td></tr></table>`,
+			"|  |  |\n| ---: | --- |\n| 1 | 2 |",
+		},
+		{
+			"block editor",
+			`<figure class="wp-block-table"><table><tbody><tr><td class="has-text-align-center" data-align="center">a</td></tr></tbody></table></figure>`,
+			"|  |\n| :---: |\n| a |",
+		},
+		{
+			"pipes",
+			`<table><tr><td>a|b</td><td><code>x || y</code></td></tr></table>`,
+			"|  |  |\n| --- | --- |\n| a\\|b | `x \\|\\| y` |",
+		},
+		{
+			"line breaks",
+			"<table><tr><td>a<br>b</td><td>c\n  d</td></tr></table>",
+			"|  |  |\n| --- | --- |\n| a<br>b | c d |",
+		},
+		{
+			"inline formatting",
+			`<table><tr><td><b>a</b> <a href="/b/">b</a></td></tr></table>`,
+			"|  |\n| --- |\n| **a** [b](/b/) |",
+		},
+		{
+			"caption",
+			`<table><caption>Ages</caption><tr><th>Name</th></tr><tr><td>Ann</td></tr></table>`,
+			"| Name |\n| --- |\n| Ann |\n\nAges",
+		},
+		{
+			"figcaption",
+			`<figure class="wp-block-table"><table><tr><td>a</td></tr></table><figcaption>Ages</figcaption></figure>`,
+			"|  |\n| --- |\n| a |\n\nAges",
+		},
+		{
+			"caption only",
+			`<table><caption>Nothing</caption></table>`,
+			"Nothing",
+		},
+		{
+			"colspan",
+			`<table><tr><td colspan="2">a</td></tr><tr><td>b</td><td>c</td></tr></table>`,
+			`<table><tbody><tr><td colspan="2">a</td></tr><tr><td>b</td><td>c</td></tr></tbody></table>`,
+		},
+		{
+			"rowspan",
+			`<table><tr><td rowspan="2">a</td><td>b</td></tr><tr><td>c</td></tr></table>`,
+			`<table><tbody><tr><td rowspan="2">a</td><td>b</td></tr><tr><td>c</td></tr></tbody></table>`,
+		},
+		{
+			"between paragraphs",
+			`<p>a</p><table><tr><td>b</td></tr></table><p>c</p>`,
+			"a\n\n|  |\n| --- |\n| b |\n\nc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.src); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisitList(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unordered", `<ul><li>a</li><li>b</li></ul>`, "* a\n* b"},
+		{"ordered", `<ol><li>a</li><li>b</li></ol>`, "1. a\n2. b"},
+		{"start", `<ol start="9"><li>a</li><li>b</li></ol>`, "9. a\n10. b"},
+		{"invalid start", `<ol start="x"><li>a</li></ol>`, "1. a"},
+		{"reversed", `<ol reversed><li>a</li><li>b</li></ol>`, "<ol reversed=\"\"><li>a</li><li>b</li></ol>"},
+		{
+			"nested",
+			`<ul><li>a<ul><li>b<ol><li>c</li></ol></li></ul></li><li>d</li></ul>`,
+			"* a\n  * b\n    1. c\n* d",
+		},
+		{
+			"nested in wide marker",
+			`<ol start="10"><li>a<ul><li>b</li></ul></li></ol>`,
+			"10. a\n    * b",
+		},
+		{
+			"loose",
+			`<ul><li><p>a</p><p>b</p></li><li>c</li></ul>`,
+			"* a\n\n  b\n\n* c",
+		},
+		{
+			"code in item",
+			"<ol><li>Run:<pre>go test\ngo vet</pre></li></ol>",
+			"1. Run:\n\n   ```\n   go test\n   go vet\n   ```",
+		},
+		{
+			"quote in item",
+			`<ul><li><blockquote><p>a</p><p>b</p></blockquote></li></ul>`,
+			"* > a\n  >\n  > b",
+		},
+		{"hard break in item", `<ul><li>a<br>b</li></ul>`, "* a  \n  b"},
+		{"paragraphs in bare text", "<ul><li>a\n\nb</li><li>c</li></ul>", "* a\n\n  b\n\n* c"},
+		{"text and nested list", "<ul><li>a<ul><li>b</li></ul><ul><li>c</li></ul></li></ul>", "* a\n  * b\n  <!-- -->\n  * c"},
+		{"adjacent lists", `<ul><li>a</li></ul><ul><li>b</li></ul>`, "* a\n\n<!-- -->\n\n* b"},
+		{"adjacent ordered lists", `<ol><li>a</li></ol><ol><li>b</li></ol>`, "1. a\n\n<!-- -->\n\n1. b"},
+		{"adjacent lists of different kinds", `<ul><li>a</li></ul><ol><li>b</li></ol>`, "* a\n\n1. b"},
+		{"lists apart", `<ul><li>a</li></ul><p>b</p><ul><li>c</li></ul>`, "* a\n\nb\n\n* c"},
+		{"lists apart in bare text", "<ul><li>a</li></ul>\nb\n<ul><li>c</li></ul>", "* a\n\nb\n\n* c"},
+		{"empty item", `<ul><li></li><li>a</li></ul>`, "*\n* a"},
+		{
+			"tasks",
+			`<ul><li><input type="checkbox" checked disabled> a</li><li><input type="checkbox"> b</li></ul>`,
+			"* [x] a\n* [ ] b",
+		},
+		{"checkbox later in item", `<ul><li>a <input type="checkbox"></li></ul>`, "* a"},
+		{"between paragraphs", `<p>a</p><ul><li>b</li></ul><p>c</p>`, "a\n\n* b\n\nc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.src); got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", `<pre>x := 1</pre>`, "```\nx := 1\n```"},
+		{"language", `<pre class="wp-block-code"><code class="language-go">x := 1</code></pre>`, "```go\nx := 1\n```"},
+		{"syntaxhighlighter", `<pre class="brush: go; notranslate">x := 1</pre>`, "```go\nx := 1\n```"},
+		{"enlighter", `<pre data-enlighter-language="go">x := 1</pre>`, "```go\nx := 1\n```"},
+		{"empty", `<pre class="wp-block-code"></pre>`, "```\n```"},
+		{"trailing newlines", "<pre>a\n\n</pre>", "```\na\n```"},
+		{"indentation kept", "<pre>if x {\n\ty()\n}</pre>", "```\nif x {\n\ty()\n}\n```"},
+		{"markdown kept", `<pre>*a* &lt;b&gt;</pre>`, "```\n*a* <b>\n```"},
+		{"short backtick runs", "<pre>`a` ``b``</pre>", "```\n`a` ``b``\n```"},
+		{"fence in code", "<pre>```go\nx\n```</pre>", "````\n```go\nx\n```\n````"},
+		{"long backtick run", "<pre>a `````` b</pre>", "```````\na `````` b\n```````"},
+		{"between paragraphs", `<p>a</p><pre>b</pre><p>c</p>`, "a\n\n```\nb\n```\n\nc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(t, tt.src); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// setFlag sets the command-line flag name to value for the duration of
+// the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	old := flag.Lookup(name).Value.String()
+	if err := flag.Set(name, value); err != nil {
+		t.Fatalf("setting -%s failed: %v", name, err)
+	}
+	t.Cleanup(func() { flag.Set(name, old) })
+}
+
+// testTaxonomies returns the taxonomies of a channel with a hierarchy of
+// categories, categories whose names can't be paths, and a tag.
+func testTaxonomies() map[string]*wxr.Taxonomy {
+	c := wxr.Channel{
+		Categories: []wxr.Category{
+			{TermID: 1, NiceName: "news", Name: "News", Description: "All the news"},
+			{TermID: 2, NiceName: "local", Parent: "news", Name: "Local", Description: "Close to home"},
+			{TermID: 4, NiceName: "whats-new", Name: "What's new?", Description: "Recent changes"},
+			{TermID: 5, NiceName: "up", Name: "..", Description: "Escapes"},
+			{TermID: 6, NiceName: "acdc", Name: "AC/DC", Description: "Rock"},
+			{TermID: 7, NiceName: "misc", Name: "Misc"},
+		},
+		Tags: []wxr.Tag{{TermID: 3, Slug: "go", Name: "Go Lang"}},
+	}
+	return c.Taxonomies()
+}
+
+func TestMenuURL(t *testing.T) {
+	post := &wxr.Item{PostName: "hello", PostType: "post", PostDate: "2021-03-04 05:06:07"}
+	page := &wxr.Item{PostName: "about", PostType: "page", PostDate: "2021-03-04 05:06:07"}
+	local := &wxr.Term{Taxonomy: wxr.TaxonomyCategory, Slug: "local", Name: "Local"}
+
+	tests := []struct {
+		name      string
+		item      wxr.MenuItem
+		termPaths bool
+		want      string
+	}{
+		{"post", wxr.MenuItem{Post: post, URL: "https://example.com/?p=1"}, false, "/posts/2021-03-04-hello/"},
+		{"page", wxr.MenuItem{Post: page, URL: "https://example.com/about/"}, false, "/about/"},
+		{"undated", wxr.MenuItem{Post: &wxr.Item{PostName: "draft", PostType: "post"}, URL: "https://example.com/?p=2"}, false, "https://example.com/?p=2"},
+		{"attachment", wxr.MenuItem{Post: &wxr.Item{PostType: "attachment"}, URL: "https://example.com/a.png"}, false, "https://example.com/a.png"},
+		{"category", wxr.MenuItem{Term: local}, false, "/categories/local/"},
+		{"category path", wxr.MenuItem{Term: local}, true, "/categories/news/local/"},
+		{"category not in the export", wxr.MenuItem{Term: &wxr.Term{Taxonomy: wxr.TaxonomyCategory, Slug: "x", Name: "Gone"}}, true, "/categories/gone/"},
+		{"tag", wxr.MenuItem{Term: &wxr.Term{Taxonomy: wxr.TaxonomyTag, Slug: "go", Name: "Go Lang"}}, false, "/tags/go-lang/"},
+		{"custom taxonomy", wxr.MenuItem{Term: &wxr.Term{Taxonomy: "genre"}, URL: ""}, false, ""},
+		{"custom link", wxr.MenuItem{URL: "https://example.org/"}, false, "https://example.org/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, "term-paths", strconv.FormatBool(tt.termPaths))
+			if got := menuURL(testTaxonomies(), &tt.item); got != tt.want {
+				t.Errorf("menuURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUrlize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"News", "news"},
+		{"Go Lang", "go-lang"},
+		{"  Go   Lang  ", "go-lang"},
+		{"What's new?", "whats-new"},
+		{"C++ & C#", "c++-c#"},
+		{"a - b", "a-b"},
+		{"v1.2_beta", "v1.2_beta"},
+		{"Ünïcödé Straße", "ünïcödé-straße"},
+		{"日本語", "日本語"},
+		{"!?", ""},
+	}
+
+	for _, tt := range tests {
+		if got := urlize(tt.in); got != tt.want {
+			t.Errorf("urlize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTermPagePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		names  []string
+		want   string
+		wantOK bool
+	}{
+		{"name", []string{"News"}, "categories/news", true},
+		{"path", []string{"News", "Local Events"}, "categories/news/local-events", true},
+		{"punctuation", []string{"What's new?"}, "categories/whats-new", true},
+		{"dots in a name", []string{"v1.2"}, "categories/v1.2", true},
+		{"dot dot", []string{".."}, "", false},
+		{"dot dot in a path", []string{"News", ".."}, "", false},
+		{"dots and punctuation", []string{". ?"}, "", false},
+		{"slash", []string{"AC/DC"}, "", false},
+		{"backslash", []string{`..\..`}, "", false},
+		{"nothing left", []string{"???"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := termPagePath("categories", tt.names)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("termPagePath(%q) = %q, %v, want %q, %v", tt.names, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWriteTermPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		termPaths bool
+		want      []string
+	}{
+		{
+			"names",
+			false,
+			[]string{"content/categories/local/_index.md", "content/categories/news/_index.md", "content/categories/whats-new/_index.md"},
+		},
+		{
+			"paths",
+			true,
+			[]string{"content/categories/news/_index.md", "content/categories/news/local/_index.md", "content/categories/whats-new/_index.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			setFlag(t, "outdir", out)
+			setFlag(t, "term-paths", strconv.FormatBool(tt.termPaths))
+
+			writeTermPages(testTaxonomies())
+
+			if got := listFiles(t, out); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+
+			b, err := os.ReadFile(filepath.Join(out, "content/categories/whats-new/_index.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "---\ntitle: \"What's new?\"\ndescription: \"Recent changes\"\n---\n\nRecent changes\n"
+			if string(b) != want {
+				t.Errorf("term page = %q, want %q", b, want)
+			}
+		})
+	}
+}
+
+// listFiles returns the paths of the files in dir, relative to it and
+// with slashes.
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// testChannel is the channel of a blog served from the root of its site.
+var testChannel = &wxr.Channel{BaseSiteUrl: "https://example.com", BaseBlogUrl: "https://example.com"}
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		item wxr.Item
+		want []string
+	}{
+		{
+			"post permalink",
+			wxr.Item{Link: "https://example.com/2021/03/hello/", PostName: "hello", PostType: "post", PostDate: "2021-03-04 05:06:07"},
+			[]string{"/2021/03/hello/"},
+		},
+		{
+			"page at its new path",
+			wxr.Item{Link: "https://example.com/about/", PostName: "about", PostType: "page", PostDate: "2021-03-04 05:06:07"},
+			nil,
+		},
+		{
+			"page at its new path without a slash",
+			wxr.Item{Link: "https://example.com/about", PostName: "about", PostType: "page", PostDate: "2021-03-04 05:06:07"},
+			nil,
+		},
+		{
+			"renamed page",
+			wxr.Item{Link: "https://example.com/about-us/", PostName: "about", PostType: "page", PostDate: "2021-03-04 05:06:07"},
+			[]string{"/about-us/"},
+		},
+		{
+			"child page",
+			wxr.Item{Link: "https://example.com/about/team/", PostName: "team", PostType: "page", PostDate: "2021-03-04 05:06:07"},
+			[]string{"/about/team/"},
+		},
+		{"query", wxr.Item{Link: "https://example.com/?p=9", PostType: "post"}, nil},
+		{"blog root", wxr.Item{Link: "https://example.com/", PostType: "page"}, nil},
+		{"other site", wxr.Item{Link: "https://example.org/hello/", PostType: "post"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aliases(testChannel, tt.item); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("aliases() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"link", `<a href="https://example.com/about/">a</a>`, "[a](/about/)"},
+		{"image", `<img src="http://www.example.com/wp-content/uploads/a.png">`, "![](/wp-content/uploads/a.png)"},
+		{"query", `<a href="https://example.com/?p=9">a</a>`, "[a](/?p=9)"},
+		{"other site", `<a href="https://example.org/about/">a</a>`, "[a](https://example.org/about/)"},
+		{"mailto", `<a href="mailto:me@example.com">a</a>`, "[a](mailto:me@example.com)"},
+		{"image link left alone", `<img href="https://example.com/a/" src="https://example.org/a.png">`, "![](https://example.org/a.png)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := html.Parse(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("parsing %q failed: %v", tt.src, err)
+			}
+			rewriteLinks(testChannel, doc)
+			if got := visitMarkdown(markdown.FromHTMLNode(doc)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMenus(t *testing.T) {
+	about := &wxr.Item{PostName: "about", PostType: "page", PostDate: "2021-03-04 05:06:07"}
+	local := &wxr.Term{Taxonomy: wxr.TaxonomyCategory, Slug: "local", Name: "Local"}
+
+	tests := []struct {
+		name      string
+		menus     []*wxr.Menu
+		termPaths bool
+		want      string
+	}{
+		{
+			"nested",
+			[]*wxr.Menu{{Slug: "main", Items: []*wxr.MenuItem{
+				{ID: 10, Title: "About", Post: about, Children: []*wxr.MenuItem{
+					{ID: 11, Title: "Local news", Term: local},
+					{ID: 12, Title: `Say "hi"`, URL: "mailto:me@example.com"},
+				}},
+				{ID: 13, Title: "GitHub", URL: "https://github.com/"},
+			}}},
+			true,
+			`
+[["main"]]
+  identifier = "menu-item-10"
+  name = "About"
+  url = "/about/"
+  weight = 1
+
+[["main"]]
+  identifier = "menu-item-11"
+  parent = "menu-item-10"
+  name = "Local news"
+  url = "/categories/news/local/"
+  weight = 1
+
+[["main"]]
+  identifier = "menu-item-12"
+  parent = "menu-item-10"
+  name = "Say \"hi\""
+  url = "mailto:me@example.com"
+  weight = 2
+
+[["main"]]
+  identifier = "menu-item-13"
+  name = "GitHub"
+  url = "https://github.com/"
+  weight = 2
+`,
+		},
+		{
+			"several menus",
+			[]*wxr.Menu{
+				{Slug: "main", Items: []*wxr.MenuItem{{ID: 10, Title: "Local", Term: local}}},
+				{Slug: "footer", Items: []*wxr.MenuItem{{ID: 20, Title: "Home", URL: "/"}}},
+			},
+			false,
+			`
+[["main"]]
+  identifier = "menu-item-10"
+  name = "Local"
+  url = "/categories/local/"
+  weight = 1
+
+[["footer"]]
+  identifier = "menu-item-20"
+  name = "Home"
+  url = "/"
+  weight = 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			setFlag(t, "outdir", out)
+			setFlag(t, "term-paths", strconv.FormatBool(tt.termPaths))
+
+			writeMenus(tt.menus, testTaxonomies())
+
+			b, err := os.ReadFile(filepath.Join(out, "config/_default/menus.toml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMenusNone(t *testing.T) {
+	out := t.TempDir()
+	setFlag(t, "outdir", out)
+
+	writeMenus(nil, nil)
+
+	if files := listFiles(t, out); len(files) != 0 {
+		t.Errorf("wrote %q, want nothing", files)
+	}
+}
+
+func TestWriteComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []wxr.Comment
+		want     string
+	}{
+		{
+			"thread",
+			[]wxr.Comment{
+				{ID: 1, Author: "Ann", AuthorURL: "https://ann.example.com/", Date: "2021-03-04 05:06:07", DateGMT: "2021-03-04 04:06:07", Content: "<p>First & \"best\"</p>", Approved: "1"},
+				{ID: 2, Parent: 1, Author: "Bob", AuthorEmail: "bob@example.com", Date: "2021-03-05 06:07:08", Content: "Reply", Approved: "1", Type: "comment"},
+			},
+			`[
+  {
+    "id": 1,
+    "author": "Ann",
+    "author_url": "https://ann.example.com/",
+    "date": "2021-03-04 04:06:07",
+    "content": "\u003cp\u003eFirst \u0026 \"best\"\u003c/p\u003e"
+  },
+  {
+    "id": 2,
+    "parent": 1,
+    "author": "Bob",
+    "date": "2021-03-05 06:07:08",
+    "content": "Reply"
+  }
+]
+`,
+		},
+		{
+			"left out",
+			[]wxr.Comment{
+				{ID: 1, Author: "Spammer", Content: "Buy", Approved: "spam"},
+				{ID: 2, Author: "Pending", Content: "Hi", Approved: "0"},
+				{ID: 3, Author: "Blog", Content: "Linked", Approved: "1", Type: "pingback"},
+				{ID: 4, Author: "Blog", Content: "Linked", Approved: "1", Type: "trackback"},
+			},
+			"",
+		},
+		{"none", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			setFlag(t, "outdir", out)
+
+			writeComments("2021-03-04-hello", wxr.Item{Title: "Hello", Comments: tt.comments})
+
+			b, err := os.ReadFile(filepath.Join(out, "data/comments/2021-03-04-hello.json"))
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("wrote %q, want no file", b)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/template"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// menuEntry is a single entry of a navigation menu as it is written to the
+// generator's configuration. Nested entries refer to their parent by its
+// Identifier.
+type menuEntry struct {
+	Identifier string
+	Parent     string
+	Name       string
+	URL        string
+	Weight     int
+}
+
+// menu is a navigation menu with its entries flattened in menu order.
+type menu struct {
+	Name    string
+	Entries []menuEntry
+}
+
+// appendMenuItem appends what wxr.Menus needs to know about item to items:
+// menu items are kept whole, and posts and pages only keep what is needed
+// to link to them.
+func appendMenuItem(items []wxr.Item, item wxr.Item) []wxr.Item {
+	switch item.PostType {
+	case wxr.PostTypeMenuItem:
+		return append(items, item)
+	case "post", "page":
+		return append(items, wxr.Item{
+			Title:       item.Title,
+			Link:        item.Link,
+			PostID:      item.PostID,
+			PostDate:    item.PostDate,
+			PostDateGMT: item.PostDateGMT,
+			PostName:    item.PostName,
+			PostType:    item.PostType,
+		})
+	}
+	return items
+}
+
+// writeMenus saves the site's navigation menus to the generator's menu
+// configuration, linking entries to the converted content wherever
+// possible. taxonomies holds the terms entries may link to.
+func writeMenus(menus []*wxr.Menu, taxonomies map[string]*wxr.Taxonomy) {
+	if len(menus) == 0 {
+		return
+	}
+
+	tmpl, ok := Menus[*generator]
+	if !ok {
+		log.Printf("generator %q doesn't support menus, skipping", *generator)
+		return
+	}
+
+	var out []menu
+	for _, m := range menus {
+		var entries []menuEntry
+		flattenMenu(&entries, taxonomies, "", m.Items)
+		out = append(out, menu{Name: m.Slug, Entries: entries})
+	}
+
+	dir := configDir(*outputDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("failed to make output directory %q: %v", dir, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s/menus.toml", dir)
+	file, err := os.Create(filename)
+	if err != nil {
+		log.Printf("unable to create file %q: %v", filename, err)
+		return
+	}
+	defer file.Close()
+
+	t := template.Must(template.New(*generator + "-menus").Parse(tmpl))
+	if err := t.Execute(file, out); err != nil {
+		log.Printf("writing menus to %q failed: %v", filename, err)
+		return
+	}
+	log.Printf("%d menus => %q", len(out), filename)
+}
+
+func flattenMenu(entries *[]menuEntry, taxonomies map[string]*wxr.Taxonomy, parent string, items []*wxr.MenuItem) {
+	for i, mi := range items {
+		e := menuEntry{
+			Identifier: fmt.Sprintf("menu-item-%d", mi.ID),
+			Parent:     parent,
+			Name:       mi.Title,
+			URL:        menuURL(taxonomies, mi),
+			Weight:     i + 1,
+		}
+		*entries = append(*entries, e)
+		flattenMenu(entries, taxonomies, e.Identifier, mi.Children)
+	}
+}
+
+// menuURL returns where a menu entry should link to on the converted site.
+// Entries that link to categories and tags link to the term pages that
+// the generator builds for the names in the front matter of posts.
+func menuURL(taxonomies map[string]*wxr.Taxonomy, mi *wxr.MenuItem) string {
+	if p := mi.Post; p != nil {
+		if u, ok := contentURL(*p); ok {
+			return u
+		}
+	}
+
+	if t := mi.Term; t != nil && (t.Taxonomy == wxr.TaxonomyCategory || t.Taxonomy == wxr.TaxonomyTag) {
+		names := []string{t.Name}
+		if tx, ok := taxonomies[t.Taxonomy]; ok {
+			if tt, ok := tx.Term(t.Slug); ok {
+				names = termNames(tt)
+			}
+		}
+		if p, ok := termPagePath(taxonomySections[t.Taxonomy], names); ok {
+			return "/" + p + "/"
+		}
+	}
+
+	return mi.URL
+}
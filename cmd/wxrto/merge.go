@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// merge writes the WXR files named by paths to out as a single export and
+// returns the exit status.
+func merge(out io.Writer, paths []string) int {
+	if len(paths) == 0 {
+		log.Print("merge: no WXR files given")
+		return 2
+	}
+
+	var docs []*wxr.RSS
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		rss, err := wxr.Decode(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			log.Printf("%s: %v", path, err)
+			return 1
+		}
+		docs = append(docs, rss)
+	}
+
+	merged, remaps := wxr.Merge(docs...)
+	for _, r := range remaps {
+		log.Printf("%s: post %d renumbered to %d", paths[r.Doc], r.From, r.To)
+	}
+
+	w := bufio.NewWriter(out)
+	if err := wxr.Encode(w, merged); err != nil {
+		log.Print(err)
+		return 1
+	}
+	if err := w.Flush(); err != nil {
+		log.Print(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// split writes the WXR file as several smaller ones to the output
+// directory and returns the exit status.
+func split(in io.Reader) int {
+	rss, err := wxr.Decode(in)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	parts, err := wxr.Split(rss, wxr.SplitOptions{
+		MaxBytes:   *splitBytes,
+		MaxItems:   *splitItems,
+		ByPostType: *splitByType,
+		ByYear:     *splitByYear,
+	})
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Printf("failed to make output directory %q: %v", *outputDir, err)
+		return 1
+	}
+
+	// Name the parts after the input, e.g. export-001.xml
+	base := "export"
+	if *inputFile != "" {
+		base = strings.TrimSuffix(filepath.Base(*inputFile), filepath.Ext(*inputFile))
+	}
+
+	for i, p := range parts {
+		filename := filepath.Join(*outputDir, fmt.Sprintf("%s-%03d.xml", base, i+1))
+		if err := writeExport(filename, p); err != nil {
+			log.Print(err)
+			return 1
+		}
+		log.Printf("%d items => %q", len(p.Channel.Items), filename)
+	}
+	return 0
+}
+
+func writeExport(filename string, rss *wxr.RSS) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := wxr.Encode(w, rss); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -5,10 +5,70 @@ var hugoPostTmpl = `---
 title: {{printf "%s" .Title}}
 date: {{.Date}}
 draft: {{.Draft}}
+{{- with .GUID}}
+guid: {{printf "%q" .}}
+{{- end}}
+{{- with .Aliases}}
+aliases:
+{{- range .}}
+  - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- with .Categories}}
+categories:
+{{- range .}}
+  - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- with .Tags}}
+tags:
+{{- range .}}
+  - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- range $taxonomy, $terms := .Taxonomies}}
+{{$taxonomy}}:
+{{- range $terms}}
+  - {{printf "%q" .}}
+{{- end}}
+{{- end}}
 ---
 
 `
 
+// hugoTermTmpl is the landing page of a taxonomy term for the Hugo static
+// site generator, which carries the term's description.
+var hugoTermTmpl = `---
+title: {{printf "%q" .Name}}
+description: {{printf "%q" .Description}}
+---
+
+{{.Description}}
+`
+
+// hugoMenusTmpl is the menu configuration for the Hugo static site
+// generator, written to config/_default/menus.toml.
+var hugoMenusTmpl = `{{- range .}}{{$menu := .Name}}
+{{- range .Entries}}
+[[{{printf "%q" $menu}}]]
+  identifier = {{printf "%q" .Identifier}}
+{{- with .Parent}}
+  parent = {{printf "%q" .}}
+{{- end}}
+  name = {{printf "%q" .Name}}
+  url = {{printf "%q" .URL}}
+  weight = {{.Weight}}
+{{end}}
+{{- end}}`
+
 var Posts = map[string]string{
 	"hugo": hugoPostTmpl,
 }
+
+var Terms = map[string]string{
+	"hugo": hugoTermTmpl,
+}
+
+var Menus = map[string]string{
+	"hugo": hugoMenusTmpl,
+}
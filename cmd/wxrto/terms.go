@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// taxonomySections maps the taxonomies WordPress has built in to the
+// sections the generator files their term pages under. Other taxonomies
+// keep their own name.
+var taxonomySections = map[string]string{
+	wxr.TaxonomyCategory: "categories",
+	wxr.TaxonomyTag:      "tags",
+}
+
+// writeTermPages gives every term that has a description in WordPress a
+// landing page in its taxonomy, so that the description carries over to
+// the term's page in the generated site.
+func writeTermPages(taxonomies map[string]*wxr.Taxonomy) {
+	tmpl, ok := Terms[*generator]
+	if !ok {
+		return
+	}
+	t := template.Must(template.New(*generator + "-term").Parse(tmpl))
+
+	for name, tx := range taxonomies {
+		if name == wxr.TaxonomyNavMenu {
+			continue
+		}
+		section, ok := taxonomySections[name]
+		if !ok {
+			section = name
+		}
+
+		for _, term := range tx.Terms {
+			if term.Description == "" {
+				continue
+			}
+			writeTermPage(t, section, term)
+		}
+	}
+}
+
+func writeTermPage(t *template.Template, section string, term *wxr.TaxonomyTerm) {
+	// The page has to be where the generator files the term named in the
+	// front matter of posts, see termName.
+	p, ok := termPagePath(section, termNames(term))
+	if !ok {
+		log.Printf("%q can't be part of a path, skipping its term page", termPath(term))
+		return
+	}
+
+	dir := fmt.Sprintf("%s/%s", contentDir(*outputDir), p)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("failed to make output directory %q: %v", dir, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s/_index.md", dir)
+	file, err := os.Create(filename)
+	if err != nil {
+		log.Printf("unable to create file %q: %v", filename, err)
+		return
+	}
+	defer file.Close()
+
+	if err := t.Execute(file, term); err != nil {
+		log.Printf("writing %q failed: %v", filename, err)
+		return
+	}
+	log.Printf("%q => %q", term.Name, filename)
+}
+
+// termPath returns how term is named in the front matter of posts: by its
+// name or, with -term-paths, by its path from the root of its taxonomy,
+// e.g. "News/Local".
+func termPath(term *wxr.TaxonomyTerm) string {
+	return strings.Join(termNames(term), "/")
+}
+
+// termNames returns the names that make up the termPath of term.
+func termNames(term *wxr.TaxonomyTerm) []string {
+	if !*termPaths {
+		return []string{term.Name}
+	}
+
+	var names []string
+	for _, p := range term.Path() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// termPagePath returns the path, relative to the content directory, of
+// the page the generator builds in section for the term whose termPath is
+// made of names, e.g. "categories/news/local". It reports false if a name
+// can't safely be part of a path: one that holds a path separator or
+// that is left empty or as dots only by urlize.
+func termPagePath(section string, names []string) (string, bool) {
+	segments := []string{section}
+	for _, name := range names {
+		if strings.ContainsAny(name, `/\`) {
+			return "", false
+		}
+		seg := urlize(name)
+		if strings.Trim(seg, ".") == "" {
+			return "", false
+		}
+		segments = append(segments, seg)
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// urlize converts a term name into the path segment the generator files
+// its taxonomy page under, as Hugo's urlize does: letters and digits are
+// kept and lowercased, spaces become dashes, and punctuation other than
+// ".", "_", "-", "#", "+", "~" and "@" is removed.
+func urlize(name string) string {
+	var b strings.Builder
+	var last rune
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || strings.ContainsRune("._#+~-@", r):
+			if space && r != '-' && last != '-' {
+				b.WriteByte('-')
+			}
+			space = false
+			b.WriteRune(r)
+			last = r
+		case unicode.IsSpace(r) && b.Len() > 0:
+			space = true
+		}
+	}
+	return b.String()
+}
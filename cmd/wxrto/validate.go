@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/connorkuehl/wxr"
+)
+
+// validate reports the structural problems in the WXR file on stderr and
+// returns the exit status: 1 if any of them are errors, 0 otherwise.
+func validate(in io.Reader) int {
+	rss, err := wxr.Decode(in)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	diags := wxr.Validate(rss)
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	if wxr.HasErrors(diags) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,609 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// A Decoder reads a WordPress E(x)tended RSS document from an input
+// stream one item at a time.
+//
+// The channel-level metadata (authors, categories, terms, etc.) that
+// precedes the items is decoded as the stream is read and is available
+// through Channel. Items are never accumulated by the Decoder, so memory
+// use stays flat regardless of the size of the export.
+//
+//	dec := wxr.NewDecoder(r)
+//	for dec.Next() {
+//		item := dec.Item()
+//		...
+//	}
+//	if err := dec.Err(); err != nil {
+//		...
+//	}
+type Decoder struct {
+	// Lenient makes the Decoder recover from the kinds of damage commonly
+	// found in real exports instead of failing. Illegal characters are
+	// removed from the input and invalid UTF-8 is replaced with U+FFFD,
+	// CDATA sections that are missing their "]]>" are closed, and an item
+	// that still can't be decoded is skipped. Each of these recoveries is
+	// recorded in Warnings, once per element for characters. HTML entities
+	// are accepted as well.
+	//
+	// Lenient must be set before the first call to Next.
+	Lenient bool
+
+	r   io.Reader
+	d   *xml.Decoder
+	rss RSS
+
+	// san sanitizes the input of a lenient Decoder.
+	san *sanitizer
+
+	// root is the document's <rss> start element.
+	root xml.StartElement
+
+	// ns holds the namespaces declared on the <rss> root, or nil if the
+	// root doesn't declare a wp: namespace.
+	ns *Namespaces
+
+	// scope holds the namespace declarations made on <channel>.
+	scope []xml.Attr
+
+	item     Item
+	err      error
+	warnings []Warning
+
+	// inChannel is set once the opening <channel> tag has been consumed.
+	inChannel bool
+
+	// done is set once the closing </channel> tag has been consumed or an
+	// error has been encountered.
+	done bool
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads an entire WordPress E(x)tended RSS document from r,
+// including all of its items.
+func Decode(r io.Reader) (*RSS, error) {
+	dec := NewDecoder(r)
+
+	var items []Item
+	for dec.Next() {
+		items = append(items, dec.Item())
+	}
+	if err := dec.Err(); err != nil {
+		return nil, err
+	}
+
+	rss := dec.RSS()
+	rss.Channel.Items = items
+	return rss, nil
+}
+
+// Next advances the Decoder to the next item in the channel, which will
+// then be available through Item. It returns false when the end of the
+// channel is reached or an error occurs, after which Err reports the
+// error, if any.
+func (dec *Decoder) Next() bool {
+	if dec.done {
+		return false
+	}
+
+	if dec.d == nil {
+		dec.init()
+	}
+
+	if !dec.inChannel {
+		if err := dec.openChannel(); err != nil {
+			dec.fail(err)
+			return false
+		}
+	}
+
+	for {
+		tok, err := dec.d.Token()
+		if err == io.EOF {
+			dec.fail(io.ErrUnexpectedEOF)
+			return false
+		}
+		if err != nil {
+			dec.fail(err)
+			return false
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if dec.ns.is(tok.Name, "", "item") {
+				err := decodeItem(dec.d, tok, &dec.item, dec.ns, dec.scope)
+				if err == nil {
+					return true
+				}
+				if !dec.Lenient {
+					dec.fail(err)
+					return false
+				}
+				if err := dec.skipItem(err); err != nil {
+					dec.fail(err)
+					return false
+				}
+				if dec.done {
+					return false
+				}
+				continue
+			}
+
+			if err := decodeChannelElement(dec.d, tok, &dec.rss.Channel, dec.ns, dec.scope); err != nil {
+				dec.fail(err)
+				return false
+			}
+		case xml.EndElement:
+			// The only end element that can appear at this level is the
+			// channel's own, everything else is consumed by DecodeElement
+			// or Skip.
+			dec.done = true
+			return false
+		}
+	}
+}
+
+// Item returns the most recent item read by a call to Next.
+func (dec *Decoder) Item() Item {
+	return dec.item
+}
+
+// Err returns the first error that was encountered by the Decoder.
+func (dec *Decoder) Err() error {
+	return dec.err
+}
+
+// Channel returns the channel-level metadata that has been decoded so
+// far. Its Items are always empty.
+func (dec *Decoder) Channel() *Channel {
+	return &dec.rss.Channel
+}
+
+// RSS returns a copy of the document's root element along with the
+// channel-level metadata that has been decoded so far. The channel's
+// Items are always empty.
+func (dec *Decoder) RSS() *RSS {
+	rss := dec.rss
+	return &rss
+}
+
+// Warnings returns the problems a lenient Decoder has recovered from so
+// far.
+func (dec *Decoder) Warnings() []Warning {
+	return dec.warnings
+}
+
+func (dec *Decoder) init() {
+	if !dec.Lenient {
+		dec.d = xml.NewDecoder(dec.r)
+		return
+	}
+
+	dec.san = newSanitizer(dec.r, dec.warn)
+	dec.d = dec.lenientXMLDecoder()
+}
+
+func (dec *Decoder) lenientXMLDecoder() *xml.Decoder {
+	d := xml.NewDecoder(dec.san)
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	return d
+}
+
+func (dec *Decoder) warn(w Warning) {
+	dec.warnings = append(dec.warnings, w)
+}
+
+// skipItem records that the item being decoded failed with err and moves
+// past it. The xml.Decoder can't continue after a syntax error, so the
+// raw input is searched for the end of the item and a new one is started
+// from there.
+func (dec *Decoder) skipItem(err error) error {
+	if dec.item.Title != "" {
+		err = fmt.Errorf("wxr: skipped item %q: %w", dec.item.Title, err)
+	} else {
+		err = fmt.Errorf("wxr: skipped item: %w", err)
+	}
+	dec.warn(Warning{dec.san.n, err})
+
+	if !dec.san.skipPast("</item>") {
+		// The input ends inside the item, so there is nothing left to
+		// recover.
+		dec.done = true
+		return nil
+	}
+
+	dec.san.buf = append(dec.san.buf, rootPrefix(dec.root)...)
+	dec.d = dec.lenientXMLDecoder()
+	dec.inChannel = false
+	return dec.openChannel()
+}
+
+func (dec *Decoder) fail(err error) {
+	dec.err = err
+	dec.done = true
+}
+
+// openChannel consumes tokens up to and including the opening <channel>
+// tag, recording the attributes of the <rss> root along the way.
+func (dec *Decoder) openChannel() error {
+	inRSS := false
+
+	for {
+		tok, err := dec.d.Token()
+		if err == io.EOF {
+			if !inRSS {
+				return fmt.Errorf("wxr: no <rss> element found")
+			}
+			return fmt.Errorf("wxr: no <channel> element found")
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !inRSS {
+			if start.Name.Local != "rss" {
+				return fmt.Errorf("wxr: expected <rss> root element, got <%s>", start.Name.Local)
+			}
+			dec.root = start.Copy()
+			dec.rss.setAttrs(start)
+			if dec.rss.Wp != "" {
+				ns, err := dec.rss.Namespaces()
+				if err != nil {
+					return err
+				}
+				dec.ns = &ns
+			}
+			inRSS = true
+			continue
+		}
+
+		if start.Name.Local != "channel" {
+			if err := dec.d.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		dec.rss.Channel.XMLName = start.Name
+		dec.scope = namespaceDecls(start.Attr, nil)
+		dec.inChannel = true
+		return nil
+	}
+}
+
+// decodeChannelElement decodes start, a child of <channel> other than
+// <item>, into c. Elements that aren't part of the channel metadata are
+// kept in c.Extra, along with the namespace declarations of scope, those
+// made on <channel>, that they don't make themselves.
+func decodeChannelElement(d *xml.Decoder, start xml.StartElement, c *Channel, ns *Namespaces, scope []xml.Attr) error {
+	name := start.Name
+
+	switch {
+	case ns.is(name, "", "title"):
+		return decodeString(d, start, &c.Title)
+	case ns.is(name, "", "link"):
+		return decodeString(d, start, &c.Link)
+	case ns.is(name, "", "description"):
+		return decodeString(d, start, &c.Description)
+	case ns.is(name, "", "pubDate"):
+		return decodeString(d, start, &c.PubDate)
+	case ns.is(name, "", "language"):
+		return decodeString(d, start, &c.Language)
+	case ns.is(name, prefixWP, "wxr_version"):
+		if err := decodeString(d, start, &c.WxrVersion); err != nil {
+			return err
+		}
+		if c.WxrVersion == "" {
+			return nil
+		}
+		_, err := NamespacesFor(c.WxrVersion)
+		return err
+	case ns.is(name, prefixWP, "base_site_url"):
+		return decodeString(d, start, &c.BaseSiteUrl)
+	case ns.is(name, prefixWP, "base_blog_url"):
+		return decodeString(d, start, &c.BaseBlogUrl)
+	case ns.is(name, prefixWP, "author"):
+		var a Author
+		if err := d.DecodeElement(&a, &start); err != nil {
+			return err
+		}
+		c.Authors = append(c.Authors, a)
+	case ns.is(name, prefixWP, "category"):
+		var cat Category
+		if err := d.DecodeElement(&cat, &start); err != nil {
+			return err
+		}
+		c.Categories = append(c.Categories, cat)
+	case ns.is(name, prefixWP, "tag"):
+		var t Tag
+		if err := d.DecodeElement(&t, &start); err != nil {
+			return err
+		}
+		c.Tags = append(c.Tags, t)
+	case ns.is(name, prefixWP, "term"):
+		var t Term
+		if err := d.DecodeElement(&t, &start); err != nil {
+			return err
+		}
+		c.Terms = append(c.Terms, t)
+	case ns.is(name, "", "generator"):
+		return decodeString(d, start, &c.Generator)
+	case name.Local == "site":
+		// <site> carries its own default namespace, so only the local
+		// name is meaningful.
+		return d.DecodeElement(&c.Site, &start)
+	default:
+		var e Element
+		if err := d.DecodeElement(&e, &start); err != nil {
+			return err
+		}
+		e.inherit(scope)
+		c.Extra = append(c.Extra, e)
+	}
+
+	return nil
+}
+
+// decodeChannel decodes the <channel> element start, including its items,
+// into c.
+func decodeChannel(d *xml.Decoder, start xml.StartElement, c *Channel, ns *Namespaces) error {
+	*c = Channel{XMLName: start.Name}
+	scope := namespaceDecls(start.Attr, nil)
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if ns.is(tok.Name, "", "item") {
+				var it Item
+				if err := decodeItem(d, tok, &it, ns, scope); err != nil {
+					return err
+				}
+				c.Items = append(c.Items, it)
+				continue
+			}
+
+			if err := decodeChannelElement(d, tok, c, ns, scope); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeItem decodes the <item> element start into it. scope holds the
+// namespace declarations made on <channel>.
+func decodeItem(d *xml.Decoder, start xml.StartElement, it *Item, ns *Namespaces, scope []xml.Attr) error {
+	*it = Item{XMLName: start.Name}
+	scope = namespaceDecls(start.Attr, scope)
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if err := decodeItemElement(d, tok, it, ns, scope); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeItemElement decodes start, a child of <item>, into it. Elements
+// that aren't part of the item are kept in it.Extra, along with the
+// namespace declarations of scope that they don't make themselves.
+func decodeItemElement(d *xml.Decoder, start xml.StartElement, it *Item, ns *Namespaces, scope []xml.Attr) error {
+	name := start.Name
+
+	switch {
+	case ns.is(name, "", "title"):
+		return decodeString(d, start, &it.Title)
+	case ns.is(name, "", "link"):
+		return decodeString(d, start, &it.Link)
+	case ns.is(name, "", "pubDate"):
+		return decodeString(d, start, &it.PubDate)
+	case ns.is(name, prefixDC, "creator"):
+		return decodeString(d, start, &it.Creator)
+	case ns.is(name, "", "guid"):
+		return d.DecodeElement(&it.GUID, &start)
+	case ns.is(name, "", "description"):
+		return decodeString(d, start, &it.Description)
+	case ns.is(name, prefixContent, "encoded"):
+		return d.DecodeElement(&it.Content, &start)
+	case ns.is(name, prefixExcerpt, "encoded"):
+		return d.DecodeElement(&it.Excerpt, &start)
+	case ns.is(name, prefixWP, "post_id"):
+		return decodeInt(d, start, &it.PostID)
+	case ns.is(name, prefixWP, "post_date"):
+		return decodeString(d, start, &it.PostDate)
+	case ns.is(name, prefixWP, "post_date_gmt"):
+		return decodeString(d, start, &it.PostDateGMT)
+	case ns.is(name, prefixWP, "post_modified"):
+		return decodeString(d, start, &it.PostModified)
+	case ns.is(name, prefixWP, "post_modified_gmt"):
+		return decodeString(d, start, &it.PostModifiedGMT)
+	case ns.is(name, prefixWP, "comment_status"):
+		return decodeString(d, start, &it.CommentStatus)
+	case ns.is(name, prefixWP, "ping_status"):
+		return decodeString(d, start, &it.PingStatus)
+	case ns.is(name, prefixWP, "status"):
+		return decodeString(d, start, &it.RawStatus)
+	case ns.is(name, prefixWP, "post_name"):
+		return decodeString(d, start, &it.PostName)
+	case ns.is(name, prefixWP, "post_parent"):
+		return decodeInt(d, start, &it.PostParent)
+	case ns.is(name, prefixWP, "menu_order"):
+		return decodeInt(d, start, &it.MenuOrder)
+	case ns.is(name, prefixWP, "post_type"):
+		return decodeString(d, start, &it.PostType)
+	case ns.is(name, prefixWP, "post_password"):
+		return decodeString(d, start, &it.PostPassword)
+	case ns.is(name, prefixWP, "is_sticky"):
+		return decodeInt(d, start, &it.Sticky)
+	case ns.is(name, prefixWP, "attachment_url"):
+		return decodeString(d, start, &it.AttachmentURL)
+	case ns.is(name, "", "category"):
+		var c ItemCategory
+		if err := d.DecodeElement(&c, &start); err != nil {
+			return err
+		}
+		it.Category = append(it.Category, c)
+		return nil
+	case ns.is(name, prefixWP, "postmeta"):
+		var m PostMeta
+		if err := d.DecodeElement(&m, &start); err != nil {
+			return err
+		}
+		it.MetaKVs = append(it.MetaKVs, m)
+		return nil
+	case ns.is(name, prefixWP, "comment"):
+		var c Comment
+		if err := d.DecodeElement(&c, &start); err != nil {
+			return err
+		}
+		it.Comments = append(it.Comments, c)
+		return nil
+	default:
+		var e Element
+		if err := d.DecodeElement(&e, &start); err != nil {
+			return err
+		}
+		e.inherit(scope)
+		it.Extra = append(it.Extra, e)
+		return nil
+	}
+}
+
+// UnmarshalXML implements xml.Unmarshaler. It resolves the namespaces
+// declared on the root element so that every element of the channel and
+// its items is mapped according to the document's WXR version.
+func (r *RSS) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*r = RSS{}
+	r.setAttrs(start)
+
+	var ns *Namespaces
+	if r.Wp != "" {
+		resolved, err := r.Namespaces()
+		if err != nil {
+			return err
+		}
+		ns = &resolved
+	}
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if !ns.is(tok.Name, "", "channel") {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := decodeChannel(d, tok, &r.Channel, ns); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// UnmarshalXML implements xml.Unmarshaler. A Channel decoded on its own
+// accepts the namespaces of any supported WXR version.
+func (c *Channel) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeChannel(d, start, c, nil)
+}
+
+// UnmarshalXML implements xml.Unmarshaler. An Item decoded on its own
+// accepts the namespaces of any supported WXR version.
+func (it *Item) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return decodeItem(d, start, it, nil, nil)
+}
+
+// setAttrs records the attributes of the document's <rss> start element.
+func (r *RSS) setAttrs(start xml.StartElement) {
+	r.XMLName = start.Name
+	r.Attrs = nil
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "version":
+			r.Version = a.Value
+		case "excerpt":
+			r.Excerpt = a.Value
+		case "content":
+			r.Content = a.Value
+		case "wfw":
+			r.Wfw = a.Value
+		case "dc":
+			r.Dc = a.Value
+		case "wp":
+			r.Wp = a.Value
+		default:
+			r.Attrs = append(r.Attrs, a)
+		}
+	}
+}
+
+// namespaceDecls returns the prefixed namespace declarations among attrs
+// followed by those of outer, which they take precedence over.
+func namespaceDecls(attrs, outer []xml.Attr) []xml.Attr {
+	var decls []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			decls = append(decls, a)
+		}
+	}
+	if len(decls) == 0 {
+		return outer
+	}
+	return append(decls, outer...)
+}
+
+// inherit adds the namespace declarations of scope, which were made on an
+// ancestor, to e unless e declares the same prefix itself. e can then be
+// encoded on its own with its prefixes intact, including those used only
+// in InnerXML.
+func (e *Element) inherit(scope []xml.Attr) {
+	declared := make(map[string]bool)
+	for _, a := range e.Attrs {
+		if a.Name.Space == "xmlns" {
+			declared[a.Name.Local] = true
+		}
+	}
+	for _, a := range scope {
+		if !declared[a.Name.Local] {
+			declared[a.Name.Local] = true
+			e.Attrs = append(e.Attrs, a)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const documentValid = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:wfw="http://wellformedweb.org/CommentAPI/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/"
+>
+<channel>
+	<title>Example Blog</title>
+	<link>https://example.com</link>
+	<description>Just another WordPress site</description>
+	<pubDate>Sat, 09 Oct 2021 15:15:49 +0000</pubDate>
+	<language>en-US</language>
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<wp:base_site_url>https://example.com</wp:base_site_url>
+	<wp:base_blog_url>https://example.com</wp:base_blog_url>
+` + authorValidFragment + categoryValidFragment + termValidFragment + `
+	<generator>https://wordpress.org/?v=5.8.1</generator>
+` + itemValidFragment + `
+		<item>
+			<title>Second</title>
+			<wp:post_id>10</wp:post_id>
+			<wp:post_type>post</wp:post_type>
+		</item>
+</channel>
+</rss>
+`
+
+func TestDecoder(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(documentValid))
+
+	var ids []int
+	for dec.Next() {
+		ids = append(ids, dec.Item().PostID)
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("Decoder.Err() = %v, want nil", err)
+	}
+
+	if want := []int{9, 10}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("decoded post IDs %v, want %v", ids, want)
+	}
+
+	ch := dec.Channel()
+	if ch.Title != "Example Blog" {
+		t.Errorf("Channel().Title = %q, want %q", ch.Title, "Example Blog")
+	}
+	if ch.WxrVersion != "1.2" {
+		t.Errorf("Channel().WxrVersion = %q, want %q", ch.WxrVersion, "1.2")
+	}
+	if len(ch.Authors) != 1 || ch.Authors[0].Login != "example_author" {
+		t.Errorf("Channel().Authors = %+v, want a single example_author", ch.Authors)
+	}
+	if len(ch.Categories) != 1 || ch.Categories[0].NiceName != "category-nice-name" {
+		t.Errorf("Channel().Categories = %+v, want a single category-nice-name", ch.Categories)
+	}
+	if len(ch.Terms) != 1 || ch.Terms[0].Slug != "cat" {
+		t.Errorf("Channel().Terms = %+v, want a single cat", ch.Terms)
+	}
+	if len(ch.Items) != 0 {
+		t.Errorf("Channel().Items has %d items, want none", len(ch.Items))
+	}
+
+	if dec.Next() {
+		t.Errorf("Next() returned true after the end of the channel")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	got, err := Decode(strings.NewReader(documentValid))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var want RSS
+	if err := xml.Unmarshal([]byte(documentValid), &want); err != nil {
+		t.Fatalf("xml.Unmarshal failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Decode got %+v, want %+v", *got, want)
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty document", ""},
+		{"wrong root element", "<feed></feed>"},
+		{"missing channel", "<rss></rss>"},
+		{"truncated channel", "<rss><channel><title>x</title>"},
+		{"truncated item", "<rss><channel><item><title>x</title>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in))
+			for dec.Next() {
+			}
+			if dec.Err() == nil {
+				t.Errorf("Decoder.Err() = nil, want an error")
+			}
+		})
+	}
+}
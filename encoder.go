@@ -0,0 +1,485 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An Encoder writes a WordPress E(x)tended RSS document to an output
+// stream in the form expected by the WordPress Importer: elements carry
+// their wp:, dc:, content:, excerpt: and wfw: prefixes and free-form text
+// is wrapped in CDATA sections.
+//
+// Namespaces that the RSS being encoded doesn't declare default to those
+// of its channel's WXR version, or to DefaultVersion if it has none.
+type Encoder struct {
+	e   *xml.Encoder
+	w   io.Writer
+	err error
+
+	// prefixes maps the namespaces declared on the <rss> root of the
+	// document being encoded to their prefixes.
+	prefixes map[string]string
+}
+
+// NewEncoder returns a new Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	e := xml.NewEncoder(w)
+	e.Indent("", "\t")
+	return &Encoder{e: e, w: w}
+}
+
+// Encode writes rss to w as a complete WordPress E(x)tended RSS document.
+func Encode(w io.Writer, rss *RSS) error {
+	return NewEncoder(w).Encode(rss)
+}
+
+// Encode writes rss as a complete WordPress E(x)tended RSS document.
+func (enc *Encoder) Encode(rss *RSS) error {
+	root, ns, err := enc.open(rss)
+	if err != nil {
+		return err
+	}
+	enc.channel(&rss.Channel, ns)
+	enc.token(root.End())
+	enc.flush()
+	if enc.err != nil {
+		return enc.err
+	}
+
+	_, err = io.WriteString(enc.w, "\n")
+	return err
+}
+
+// open writes the XML header and the <rss> start element of rss, which it
+// returns along with the namespaces of rss.
+func (enc *Encoder) open(rss *RSS) (xml.StartElement, Namespaces, error) {
+	ns, err := rss.Namespaces()
+	if err != nil {
+		return xml.StartElement{}, ns, err
+	}
+
+	if _, err = io.WriteString(enc.w, xml.Header); err != nil {
+		return xml.StartElement{}, ns, err
+	}
+
+	root := xml.StartElement{
+		Name: xml.Name{Local: "rss"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "version"}, Value: orDefault(rss.Version, "2.0")},
+			{Name: xml.Name{Local: "xmlns:excerpt"}, Value: ns.Excerpt},
+			{Name: xml.Name{Local: "xmlns:content"}, Value: ns.Content},
+			{Name: xml.Name{Local: "xmlns:wfw"}, Value: ns.WFW},
+			{Name: xml.Name{Local: "xmlns:dc"}, Value: ns.DC},
+			{Name: xml.Name{Local: "xmlns:wp"}, Value: ns.WP},
+		},
+	}
+
+	enc.prefixes = map[string]string{
+		ns.Excerpt: prefixExcerpt,
+		ns.Content: prefixContent,
+		ns.WFW:     prefixWFW,
+		ns.DC:      prefixDC,
+		ns.WP:      prefixWP,
+	}
+	for _, a := range rss.Attrs {
+		if a.Name.Space == "xmlns" {
+			enc.prefixes[a.Value] = a.Name.Local
+		}
+	}
+	for _, a := range rss.Attrs {
+		if _, ok := enc.prefix(a.Name.Space, nil); !ok {
+			p := enc.newPrefix(nil)
+			enc.prefixes[a.Name.Space] = p
+			root.Attr = append(root.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: a.Name.Space})
+		}
+		root.Attr = append(root.Attr, xml.Attr{Name: enc.qualify(a.Name, nil), Value: a.Value})
+	}
+
+	enc.token(root)
+	return root, ns, enc.err
+}
+
+func (enc *Encoder) channel(c *Channel, ns Namespaces) {
+	start := xml.StartElement{Name: xml.Name{Local: "channel"}}
+	enc.token(start)
+
+	enc.text("title", c.Title)
+	enc.text("link", c.Link)
+	enc.text("description", c.Description)
+	enc.text("pubDate", c.PubDate)
+	enc.text("language", c.Language)
+	enc.text("wp:wxr_version", orDefault(c.WxrVersion, ns.Version))
+	enc.text("wp:base_site_url", c.BaseSiteUrl)
+	enc.text("wp:base_blog_url", c.BaseBlogUrl)
+
+	for i := range c.Authors {
+		enc.author(&c.Authors[i])
+	}
+	for i := range c.Categories {
+		enc.category(&c.Categories[i])
+	}
+	for i := range c.Tags {
+		enc.tag(&c.Tags[i])
+	}
+	for i := range c.Terms {
+		enc.term(&c.Terms[i])
+	}
+
+	enc.text("generator", c.Generator)
+
+	if c.Site.Xmlns != "" || c.Site.ID != 0 {
+		site := xml.StartElement{Name: xml.Name{Local: "site"}}
+		if c.Site.Xmlns != "" {
+			site.Attr = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: c.Site.Xmlns}}
+		}
+		id := ""
+		if c.Site.ID != 0 {
+			id = strconv.Itoa(c.Site.ID)
+		}
+		enc.element(site, id)
+	}
+
+	for i := range c.Extra {
+		enc.extra(&c.Extra[i])
+	}
+
+	for i := range c.Items {
+		enc.item(&c.Items[i])
+	}
+
+	enc.token(start.End())
+}
+
+func (enc *Encoder) author(a *Author) {
+	start := xml.StartElement{Name: xml.Name{Local: "wp:author"}}
+	enc.token(start)
+	enc.int("wp:author_id", a.ID)
+	enc.cdata("wp:author_login", a.Login)
+	enc.cdata("wp:author_email", a.Email)
+	enc.cdata("wp:author_display_name", a.DisplayName)
+	enc.cdata("wp:author_first_name", a.FirstName)
+	enc.cdata("wp:author_last_name", a.LastName)
+	enc.token(start.End())
+}
+
+func (enc *Encoder) category(c *Category) {
+	start := xml.StartElement{Name: xml.Name{Local: "wp:category"}}
+	enc.token(start)
+	enc.int("wp:term_id", c.TermID)
+	enc.cdata("wp:category_nicename", c.NiceName)
+	enc.cdata("wp:category_parent", c.Parent)
+	enc.cdata("wp:cat_name", c.Name)
+	if c.Description != "" {
+		enc.cdata("wp:category_description", c.Description)
+	}
+	enc.termMeta(c.MetaKVs)
+	enc.token(start.End())
+}
+
+func (enc *Encoder) tag(t *Tag) {
+	start := xml.StartElement{Name: xml.Name{Local: "wp:tag"}}
+	enc.token(start)
+	enc.int("wp:term_id", t.TermID)
+	enc.cdata("wp:tag_slug", t.Slug)
+	enc.cdata("wp:tag_name", t.Name)
+	if t.Description != "" {
+		enc.cdata("wp:tag_description", t.Description)
+	}
+	enc.termMeta(t.MetaKVs)
+	enc.token(start.End())
+}
+
+func (enc *Encoder) term(t *Term) {
+	start := xml.StartElement{Name: xml.Name{Local: "wp:term"}}
+	enc.token(start)
+	enc.int("wp:term_id", t.ID)
+	enc.cdata("wp:term_taxonomy", t.Taxonomy)
+	enc.cdata("wp:term_slug", t.Slug)
+	enc.cdata("wp:term_parent", t.Parent)
+	enc.cdata("wp:term_name", t.Name)
+	if t.Description != "" {
+		enc.cdata("wp:term_description", t.Description)
+	}
+	enc.termMeta(t.MetaKVs)
+	enc.token(start.End())
+}
+
+func (enc *Encoder) termMeta(kvs []TermMeta) {
+	for _, m := range kvs {
+		meta := xml.StartElement{Name: xml.Name{Local: "wp:termmeta"}}
+		enc.token(meta)
+		enc.cdata("wp:meta_key", m.Key)
+		enc.cdata("wp:meta_value", m.Value)
+		enc.token(meta.End())
+	}
+}
+
+func (enc *Encoder) item(it *Item) {
+	start := xml.StartElement{Name: xml.Name{Local: "item"}}
+	enc.token(start)
+
+	enc.text("title", it.Title)
+	enc.text("link", it.Link)
+	enc.text("pubDate", it.PubDate)
+	enc.cdata("dc:creator", it.Creator)
+
+	guid := xml.StartElement{Name: xml.Name{Local: "guid"}}
+	if it.GUID.IsPermaLink != "" {
+		guid.Attr = []xml.Attr{{Name: xml.Name{Local: "isPermaLink"}, Value: it.GUID.IsPermaLink}}
+	}
+	enc.element(guid, it.GUID.Value)
+
+	enc.text("description", it.Description)
+	enc.cdata("content:encoded", it.Content.Data)
+	enc.cdata("excerpt:encoded", it.Excerpt.Data)
+	enc.int("wp:post_id", it.PostID)
+	enc.cdata("wp:post_date", it.PostDate)
+	enc.cdata("wp:post_date_gmt", it.PostDateGMT)
+	enc.cdata("wp:post_modified", it.PostModified)
+	enc.cdata("wp:post_modified_gmt", it.PostModifiedGMT)
+	enc.cdata("wp:comment_status", it.CommentStatus)
+	enc.cdata("wp:ping_status", it.PingStatus)
+	enc.cdata("wp:post_name", it.PostName)
+	enc.cdata("wp:status", it.RawStatus)
+	enc.int("wp:post_parent", it.PostParent)
+	enc.int("wp:menu_order", it.MenuOrder)
+	enc.cdata("wp:post_type", it.PostType)
+	enc.cdata("wp:post_password", it.PostPassword)
+	enc.int("wp:is_sticky", it.Sticky)
+	if it.AttachmentURL != "" {
+		enc.cdata("wp:attachment_url", it.AttachmentURL)
+	}
+
+	for _, c := range it.Category {
+		enc.cdataElement(xml.StartElement{
+			Name: xml.Name{Local: "category"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "domain"}, Value: c.Domain},
+				{Name: xml.Name{Local: "nicename"}, Value: c.NiceName},
+			},
+		}, c.Name)
+	}
+
+	for _, m := range it.MetaKVs {
+		meta := xml.StartElement{Name: xml.Name{Local: "wp:postmeta"}}
+		enc.token(meta)
+		enc.cdata("wp:meta_key", m.Key)
+		enc.cdata("wp:meta_value", m.Value)
+		enc.token(meta.End())
+	}
+
+	for i := range it.Comments {
+		enc.comment(&it.Comments[i])
+	}
+
+	for i := range it.Extra {
+		enc.extra(&it.Extra[i])
+	}
+
+	enc.token(start.End())
+}
+
+func (enc *Encoder) comment(c *Comment) {
+	start := xml.StartElement{Name: xml.Name{Local: "wp:comment"}}
+	enc.token(start)
+	enc.int("wp:comment_id", c.ID)
+	enc.cdata("wp:comment_author", c.Author)
+	enc.cdata("wp:comment_author_email", c.AuthorEmail)
+	enc.text("wp:comment_author_url", c.AuthorURL)
+	enc.cdata("wp:comment_author_IP", c.AuthorIP)
+	enc.cdata("wp:comment_date", c.Date)
+	enc.cdata("wp:comment_date_gmt", c.DateGMT)
+	enc.cdata("wp:comment_content", c.Content)
+	enc.cdata("wp:comment_approved", c.Approved)
+	enc.cdata("wp:comment_type", c.Type)
+	enc.int("wp:comment_parent", c.Parent)
+	enc.int("wp:comment_user_id", c.UserID)
+
+	for _, m := range c.MetaKVs {
+		meta := xml.StartElement{Name: xml.Name{Local: "wp:commentmeta"}}
+		enc.token(meta)
+		enc.cdata("wp:meta_key", m.Key)
+		enc.cdata("wp:meta_value", m.Value)
+		enc.token(meta.End())
+	}
+
+	enc.token(start.End())
+}
+
+// token writes t unless a previous write has failed.
+func (enc *Encoder) token(t xml.Token) {
+	if enc.err != nil {
+		return
+	}
+	enc.err = enc.e.EncodeToken(t)
+}
+
+// element writes start, the escaped text s and the matching end element.
+func (enc *Encoder) element(start xml.StartElement, s string) {
+	if enc.err != nil {
+		return
+	}
+	enc.err = enc.e.EncodeElement(s, start)
+}
+
+// text writes s as the escaped character data of the element name.
+func (enc *Encoder) text(name, s string) {
+	enc.element(xml.StartElement{Name: xml.Name{Local: name}}, s)
+}
+
+// int writes n as the character data of the element name.
+func (enc *Encoder) int(name string, n int) {
+	enc.text(name, strconv.Itoa(n))
+}
+
+// cdata writes s wrapped in a CDATA section as the character data of the
+// element name.
+func (enc *Encoder) cdata(name, s string) {
+	enc.cdataElement(xml.StartElement{Name: xml.Name{Local: name}}, s)
+}
+
+// cdataElement writes start, s wrapped in a CDATA section and the matching
+// end element.
+func (enc *Encoder) cdataElement(start xml.StartElement, s string) {
+	if enc.err != nil {
+		return
+	}
+	v := struct {
+		Data string `xml:",cdata"`
+	}{s}
+	enc.err = enc.e.EncodeElement(v, start)
+}
+
+// extra writes e back out as it was decoded.
+func (enc *Encoder) extra(e *Element) {
+	// Namespaces declared on the element itself take precedence over
+	// those declared on the root.
+	var local map[string]string
+	for _, a := range e.Attrs {
+		switch {
+		case a.Name.Space == "xmlns":
+			if local == nil {
+				local = make(map[string]string)
+			}
+			local[a.Value] = a.Name.Local
+		case a.Name.Space == "" && a.Name.Local == "xmlns":
+			if local == nil {
+				local = make(map[string]string)
+			}
+			local[a.Value] = ""
+		}
+	}
+
+	// Namespaces that have no prefix in scope, e.g. because they were
+	// declared on an ancestor the element was taken out of, are given one
+	// of their own.
+	var decls []xml.Attr
+	names := []xml.Name{e.XMLName}
+	for _, a := range e.Attrs {
+		names = append(names, a.Name)
+	}
+	for _, name := range names {
+		if _, ok := enc.prefix(name.Space, local); ok {
+			continue
+		}
+		if local == nil {
+			local = make(map[string]string)
+		}
+		p := enc.newPrefix(local)
+		local[name.Space] = p
+		decls = append(decls, xml.Attr{Name: xml.Name{Local: "xmlns:" + p}, Value: name.Space})
+	}
+
+	start := xml.StartElement{Name: enc.qualify(e.XMLName, local)}
+	for _, a := range e.Attrs {
+		start.Attr = append(start.Attr, xml.Attr{Name: enc.qualify(a.Name, local), Value: a.Value})
+	}
+	start.Attr = append(start.Attr, decls...)
+
+	if enc.err != nil {
+		return
+	}
+	v := struct {
+		InnerXML string `xml:",innerxml"`
+	}{e.InnerXML}
+	enc.err = enc.e.EncodeElement(v, start)
+}
+
+// qualify returns name with its namespace replaced by the prefix it was
+// declared with, see prefix.
+func (enc *Encoder) qualify(name xml.Name, local map[string]string) xml.Name {
+	switch name.Space {
+	case "":
+		return name
+	case "xmlns":
+		return xml.Name{Local: "xmlns:" + name.Local}
+	case xmlNamespace:
+		return xml.Name{Local: "xml:" + name.Local}
+	}
+
+	// The namespaces of everything that is qualified have been bound to
+	// a prefix beforehand
+	prefix, _ := enc.prefix(name.Space, local)
+
+	if prefix == "" {
+		return xml.Name{Local: name.Local}
+	}
+	return xml.Name{Local: prefix + ":" + name.Local}
+}
+
+// prefix returns the prefix that space is bound to, looking in local
+// before the root's declarations. It reports false for a namespace URL
+// that isn't bound. Any other space, such as a prefix that was never
+// resolved, as in a document fragment, is its own prefix.
+func (enc *Encoder) prefix(space string, local map[string]string) (string, bool) {
+	switch space {
+	case "":
+		return "", true
+	case xmlNamespace:
+		return "xml", true
+	}
+	if p, ok := local[space]; ok {
+		return p, true
+	}
+	if p, ok := enc.prefixes[space]; ok {
+		return p, true
+	}
+	// A prefix can contain neither, while every URL or URN does.
+	if strings.ContainsAny(space, ":/") {
+		return "", false
+	}
+	return space, true
+}
+
+// newPrefix returns a prefix that isn't bound in local or on the root.
+func (enc *Encoder) newPrefix(local map[string]string) string {
+	used := make(map[string]bool)
+	for _, p := range local {
+		used[p] = true
+	}
+	for _, p := range enc.prefixes {
+		used[p] = true
+	}
+	for i := 1; ; i++ {
+		if p := "ns" + strconv.Itoa(i); !used[p] {
+			return p
+		}
+	}
+}
+
+func (enc *Encoder) flush() {
+	if enc.err != nil {
+		return
+	}
+	enc.err = enc.e.Flush()
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,275 @@
+package wxr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clearXMLNames zeroes every xml.Name reachable from v. The fixtures in
+// wxr_test.go omit namespace prefixes, whereas an Encoder always writes
+// them, so the names recorded during decoding legitimately differ.
+func clearXMLNames(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			clearXMLNames(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			clearXMLNames(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(xml.Name{}) {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				clearXMLNames(v.Field(i))
+			}
+		}
+	}
+}
+
+func roundTrip(t *testing.T, in string) (first, second *RSS, encoded string) {
+	t.Helper()
+
+	first, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, first); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	second, err = Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Decode of encoded document failed: %v\n%s", err, buf.String())
+	}
+
+	return first, second, buf.String()
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"document", documentValid},
+		{"author fragment", wrapChannel(authorValidFragment)},
+		{"category fragment", wrapChannel(categoryValidFragment)},
+		{"term fragment", wrapChannel(termValidFragment)},
+		{"described category fragment", wrapChannel(categoryDescribedFragment)},
+		{"described term fragment", wrapChannel(termDescribedFragment)},
+		{"tag fragment", wrapChannel(tagValidFragment)},
+		{"item fragment", wrapChannel(itemValidFragment)},
+		{"item with content", wrapChannel(itemContentFragment)},
+		{"item with categories", wrapChannel(itemCategoriesFragment)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second, encoded := roundTrip(t, tt.in)
+
+			clearXMLNames(reflect.ValueOf(first))
+			clearXMLNames(reflect.ValueOf(second))
+
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("decode->encode->decode got %+v, want %+v\n%s", second, first, encoded)
+			}
+		})
+	}
+}
+
+func TestEncodePrefixes(t *testing.T) {
+	_, _, encoded := roundTrip(t, documentValid)
+
+	for _, want := range []string{
+		`xmlns:wp="http://wordpress.org/export/1.2/"`,
+		`xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"`,
+		`<wp:wxr_version>1.2</wp:wxr_version>`,
+		`<wp:author_login><![CDATA[example_author]]></wp:author_login>`,
+		`<wp:post_id>9</wp:post_id>`,
+		`<content:encoded></content:encoded>`,
+		`<excerpt:encoded></excerpt:encoded>`,
+		`<dc:creator>`,
+		`<wp:meta_key><![CDATA[_menu_item_type]]></wp:meta_key>`,
+	} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded document is missing %s\n%s", want, encoded)
+		}
+	}
+}
+
+const itemContentFragment = `
+		<item>
+			<title>A &amp; B</title>
+			<content:encoded><![CDATA[<p>Tricky ]]]]><![CDATA[> content</p>]]></content:encoded>
+			<excerpt:encoded><![CDATA[An excerpt]]></excerpt:encoded>
+			<wp:post_id>11</wp:post_id>
+			<wp:comment>
+				<wp:comment_id>1</wp:comment_id>
+				<wp:comment_content><![CDATA[First!]]></wp:comment_content>
+			</wp:comment>
+` + commentValidFragment + `
+		</item>
+`
+
+func wrapChannel(fragment string) string {
+	return `<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:wfw="http://wellformedweb.org/CommentAPI/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/"
+><channel><wp:wxr_version>1.2</wp:wxr_version>` + fragment + `</channel></rss>`
+}
+
+func TestEncodeContent(t *testing.T) {
+	first, _, _ := roundTrip(t, wrapChannel(itemContentFragment))
+
+	it := first.Channel.Items[0]
+	if want := "<p>Tricky ]]> content</p>"; it.Content.Data != want {
+		t.Errorf("Content.Data = %q, want %q", it.Content.Data, want)
+	}
+	if want := "An excerpt"; it.Excerpt.Data != want {
+		t.Errorf("Excerpt.Data = %q, want %q", it.Excerpt.Data, want)
+	}
+	if want := "A & B"; it.Title != want {
+		t.Errorf("Title = %q, want %q", it.Title, want)
+	}
+	if len(it.Comments) != 2 || it.Comments[0].Content != "First!" || it.Comments[1].ID != 42 {
+		t.Errorf("Comments = %+v, want comments 1 and 42", it.Comments)
+	}
+}
+
+const extraElementsDocument = `<rss version="2.0"
+	xmlns:wp="http://wordpress.org/export/1.2/"
+	xmlns:woo="https://woocommerce.example.com/export/"
+	xmlns:jetpack="https://jetpack.example.com/"
+><channel>
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<woo:settings currency="EUR"><woo:tax rate="0.2"/></woo:settings>
+	<item>
+		<title>Product</title>
+		<wp:post_id>3</wp:post_id>
+		<woo:price sale="true"><![CDATA[9.99]]></woo:price>
+		<jetpack:stats><jetpack:views>12</jetpack:views><note xmlns="urn:example:notes" lang="en">Hi &amp; bye</note></jetpack:stats>
+		<wp:post_format>aside</wp:post_format>
+	</item>
+</channel></rss>`
+
+func TestEncodeExtraElements(t *testing.T) {
+	first, second, encoded := roundTrip(t, extraElementsDocument)
+
+	if got := len(first.Channel.Extra); got != 1 {
+		t.Fatalf("got %d channel extras, want 1", got)
+	}
+	settings := first.Channel.Extra[0]
+	if settings.XMLName.Space != "https://woocommerce.example.com/export/" || settings.XMLName.Local != "settings" {
+		t.Errorf("channel extra is named %+v", settings.XMLName)
+	}
+
+	extra := first.Channel.Items[0].Extra
+	var names []string
+	for _, e := range extra {
+		names = append(names, e.XMLName.Local)
+	}
+	if got, want := strings.Join(names, ","), "price,stats,post_format"; got != want {
+		t.Fatalf("item extras = %s, want %s", got, want)
+	}
+	if extra[0].InnerXML != "<![CDATA[9.99]]>" || extra[0].Attrs[0].Value != "true" {
+		t.Errorf("price = %+v", extra[0])
+	}
+
+	if !reflect.DeepEqual(first.Attrs, second.Attrs) {
+		t.Errorf("root attributes changed from %+v to %+v", first.Attrs, second.Attrs)
+	}
+	if !reflect.DeepEqual(first.Channel.Extra, second.Channel.Extra) {
+		t.Errorf("channel extras changed from %+v to %+v", first.Channel.Extra, second.Channel.Extra)
+	}
+	if !reflect.DeepEqual(extra, second.Channel.Items[0].Extra) {
+		t.Errorf("item extras changed from %+v to %+v", extra, second.Channel.Items[0].Extra)
+	}
+
+	for _, want := range []string{
+		`xmlns:woo="https://woocommerce.example.com/export/"`,
+		`<woo:settings currency="EUR"><woo:tax rate="0.2"/></woo:settings>`,
+		`<woo:price sale="true"><![CDATA[9.99]]></woo:price>`,
+		`<jetpack:stats><jetpack:views>12</jetpack:views><note xmlns="urn:example:notes" lang="en">Hi &amp; bye</note></jetpack:stats>`,
+		`<wp:post_format>aside</wp:post_format>`,
+	} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded document is missing %s:\n%s", want, encoded)
+		}
+	}
+}
+
+func TestEncodeExtraElementsOuterNamespaces(t *testing.T) {
+	in := `<rss version="2.0" xmlns:wp="http://wordpress.org/export/1.2/">
+<channel xmlns:og="http://ogp.me/ns#">
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<og:locale>en_GB</og:locale>
+	<item xmlns:jp="https://jetpack.example.com/" xmlns:og="http://ogp.me/ns/article#">
+		<jp:stats rank="1"><jp:views>3</jp:views></jp:stats>
+		<og:type>article</og:type>
+	</item>
+</channel>
+</rss>`
+
+	first, second, encoded := roundTrip(t, in)
+
+	if !reflect.DeepEqual(first.Channel.Extra, second.Channel.Extra) {
+		t.Errorf("channel extras changed from %+v to %+v", first.Channel.Extra, second.Channel.Extra)
+	}
+	if !reflect.DeepEqual(first.Channel.Items[0].Extra, second.Channel.Items[0].Extra) {
+		t.Errorf("item extras changed from %+v to %+v", first.Channel.Items[0].Extra, second.Channel.Items[0].Extra)
+	}
+
+	// The item's own og: declaration takes precedence over the channel's.
+	if got := second.Channel.Items[0].Extra[1].XMLName.Space; got != "http://ogp.me/ns/article#" {
+		t.Errorf("og:type namespace = %q", got)
+	}
+
+	for _, want := range []string{
+		`<og:locale xmlns:og="http://ogp.me/ns#">en_GB</og:locale>`,
+		`<jp:stats rank="1" xmlns:jp="https://jetpack.example.com/" xmlns:og="http://ogp.me/ns/article#"><jp:views>3</jp:views></jp:stats>`,
+	} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded document is missing %s:\n%s", want, encoded)
+		}
+	}
+}
+
+func TestEncodeExtraElementsUndeclaredNamespace(t *testing.T) {
+	rss := &RSS{Channel: Channel{Items: []Item{{
+		Extra: []Element{{
+			XMLName:  xml.Name{Space: "https://jetpack.example.com/", Local: "views"},
+			Attrs:    []xml.Attr{{Name: xml.Name{Space: "urn:example:ranks", Local: "rank"}, Value: "1"}},
+			InnerXML: "3",
+		}},
+	}}}}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, rss); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	want := `<ns1:views ns2:rank="1" xmlns:ns1="https://jetpack.example.com/" xmlns:ns2="urn:example:ranks">3</ns1:views>`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("encoded document is missing %s:\n%s", want, buf.String())
+	}
+
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode of encoded document failed: %v", err)
+	}
+	e := got.Channel.Items[0].Extra[0]
+	if e.XMLName != rss.Channel.Items[0].Extra[0].XMLName || e.InnerXML != "3" {
+		t.Errorf("decoded extra = %+v", e)
+	}
+}
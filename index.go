@@ -0,0 +1,150 @@
+package wxr
+
+import "sort"
+
+// An Index resolves the relationships between the items, authors and terms
+// of a channel without scanning them.
+type Index struct {
+	byID      map[int]*Item
+	byName    map[postKey]*Item
+	byParent  map[int][]*Item
+	byCreator map[string][]*Item
+	authors   map[string]*Author
+	terms     map[termKey]*Term
+	termsByID map[int]*Term
+}
+
+type postKey struct {
+	postType string
+	name     string
+}
+
+type termKey struct {
+	taxonomy string
+	slug     string
+}
+
+// Index builds an index of the channel. The items and authors it returns
+// point into the channel, so it reflects the channel at the time it is
+// called and must be rebuilt if Items or Authors are reassigned.
+func (c *Channel) Index() *Index {
+	idx := &Index{
+		byID:      make(map[int]*Item, len(c.Items)),
+		byName:    make(map[postKey]*Item, len(c.Items)),
+		byParent:  make(map[int][]*Item),
+		byCreator: make(map[string][]*Item),
+		authors:   make(map[string]*Author, len(c.Authors)),
+		terms:     make(map[termKey]*Term),
+		termsByID: make(map[int]*Term),
+	}
+
+	for i := range c.Items {
+		it := &c.Items[i]
+		if it.PostID != 0 {
+			idx.byID[it.PostID] = it
+		}
+		if it.PostName != "" {
+			k := postKey{it.PostType, it.PostName}
+			if _, ok := idx.byName[k]; !ok {
+				idx.byName[k] = it
+			}
+		}
+		if it.PostParent != 0 {
+			idx.byParent[it.PostParent] = append(idx.byParent[it.PostParent], it)
+		}
+		if it.Creator != "" {
+			idx.byCreator[it.Creator] = append(idx.byCreator[it.Creator], it)
+		}
+	}
+
+	for i := range c.Authors {
+		a := &c.Authors[i]
+		idx.authors[a.Login] = a
+	}
+
+	for i := range c.Terms {
+		t := &c.Terms[i]
+		idx.terms[termKey{t.Taxonomy, t.Slug}] = t
+		if t.ID != 0 {
+			idx.termsByID[t.ID] = t
+		}
+	}
+
+	builtin := c.builtinTerms()
+	for i := range builtin {
+		t := &builtin[i]
+		k := termKey{t.Taxonomy, t.Slug}
+		if _, ok := idx.terms[k]; ok {
+			continue
+		}
+		idx.terms[k] = t
+		if _, ok := idx.termsByID[t.ID]; t.ID != 0 && !ok {
+			idx.termsByID[t.ID] = t
+		}
+	}
+
+	return idx
+}
+
+// Item returns the item whose post ID is id.
+func (idx *Index) Item(id int) (*Item, bool) {
+	it, ok := idx.byID[id]
+	return it, ok
+}
+
+// ItemByName returns the item of the given post type whose slug is name.
+// If several items share a slug, as drafts sometimes do, the first one in
+// the export is returned.
+func (idx *Index) ItemByName(postType, name string) (*Item, bool) {
+	it, ok := idx.byName[postKey{postType, name}]
+	return it, ok
+}
+
+// Parent returns the item's parent, if it has one and it is part of the
+// export.
+func (idx *Index) Parent(it *Item) (*Item, bool) {
+	if it.PostParent == 0 {
+		return nil, false
+	}
+	return idx.Item(it.PostParent)
+}
+
+// Children returns the items whose parent is the post with the given ID,
+// ordered the way WordPress orders them: by menu order, then by ID.
+func (idx *Index) Children(parent int) []*Item {
+	items := append([]*Item(nil), idx.byParent[parent]...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].MenuOrder != items[j].MenuOrder {
+			return items[i].MenuOrder < items[j].MenuOrder
+		}
+		return items[i].PostID < items[j].PostID
+	})
+	return items
+}
+
+// ItemsBy returns the items written by the author whose login is login,
+// in the order they appear in the export.
+func (idx *Index) ItemsBy(login string) []*Item {
+	return idx.byCreator[login]
+}
+
+// Author returns the author whose login is login.
+func (idx *Index) Author(login string) (*Author, bool) {
+	a, ok := idx.authors[login]
+	return a, ok
+}
+
+// Term returns the term of the taxonomy whose slug is slug. Categories and
+// tags are found under TaxonomyCategory and TaxonomyTag; the Domain and
+// NiceName of an ItemCategory can be passed as they are.
+func (idx *Index) Term(taxonomy, slug string) (*Term, bool) {
+	t, ok := idx.terms[termKey{taxonomy, slug}]
+	return t, ok
+}
+
+// TermByID returns the term whose term ID is id. Term IDs are shared by
+// all taxonomies, so the ID alone identifies a term.
+func (idx *Index) TermByID(id int) (*Term, bool) {
+	t, ok := idx.termsByID[id]
+	return t, ok
+}
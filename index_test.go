@@ -0,0 +1,88 @@
+package wxr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChannelIndex(t *testing.T) {
+	c := &Channel{
+		Authors: []Author{{ID: 1, Login: "alice"}, {ID: 2, Login: "bob"}},
+		Categories: []Category{
+			{TermID: 3, NiceName: "news", Name: "News"},
+		},
+		Terms: []Term{
+			{ID: 4, Taxonomy: "post_tag", Slug: "go", Name: "Go"},
+			{ID: 5, Taxonomy: "genre", Slug: "news", Name: "News Genre"},
+		},
+		Items: []Item{
+			{PostID: 1, PostName: "about", PostType: "page", Creator: "alice"},
+			{PostID: 2, PostName: "team", PostType: "page", PostParent: 1, MenuOrder: 2, Creator: "alice"},
+			{PostID: 3, PostName: "history", PostType: "page", PostParent: 1, MenuOrder: 1, Creator: "bob"},
+			{PostID: 4, PostName: "about", PostType: "post", Creator: "alice"},
+			{PostID: 5, PostName: "team", PostType: "page", PostParent: 1, MenuOrder: 2},
+		},
+	}
+	idx := c.Index()
+
+	if it, ok := idx.Item(3); !ok || it != &c.Items[2] {
+		t.Errorf("Item(3) = %v, %v, want the third item", it, ok)
+	}
+	if _, ok := idx.Item(99); ok {
+		t.Errorf("Item(99) found an item")
+	}
+
+	if it, ok := idx.ItemByName("post", "about"); !ok || it.PostID != 4 {
+		t.Errorf(`ItemByName("post", "about") = %v, %v, want post 4`, it, ok)
+	}
+	if it, ok := idx.ItemByName("page", "team"); !ok || it.PostID != 2 {
+		t.Errorf(`ItemByName("page", "team") = %v, %v, want the first match, post 2`, it, ok)
+	}
+
+	if p, ok := idx.Parent(&c.Items[1]); !ok || p.PostID != 1 {
+		t.Errorf("Parent of post 2 = %v, %v, want post 1", p, ok)
+	}
+	if _, ok := idx.Parent(&c.Items[0]); ok {
+		t.Errorf("Parent of post 1 found an item")
+	}
+
+	var children []int
+	for _, it := range idx.Children(1) {
+		children = append(children, it.PostID)
+	}
+	if want := []int{3, 2, 5}; !reflect.DeepEqual(children, want) {
+		t.Errorf("Children(1) = %v, want %v", children, want)
+	}
+
+	var byAlice []int
+	for _, it := range idx.ItemsBy("alice") {
+		byAlice = append(byAlice, it.PostID)
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(byAlice, want) {
+		t.Errorf(`ItemsBy("alice") = %v, want %v`, byAlice, want)
+	}
+
+	if a, ok := idx.Author("bob"); !ok || a.ID != 2 {
+		t.Errorf(`Author("bob") = %v, %v, want author 2`, a, ok)
+	}
+
+	tests := []struct {
+		taxonomy, slug string
+		wantID         int
+	}{
+		{TaxonomyCategory, "news", 3},
+		{TaxonomyTag, "go", 4},
+		{"genre", "news", 5},
+	}
+	for _, tt := range tests {
+		if term, ok := idx.Term(tt.taxonomy, tt.slug); !ok || term.ID != tt.wantID {
+			t.Errorf("Term(%q, %q) = %v, %v, want term %d", tt.taxonomy, tt.slug, term, ok, tt.wantID)
+		}
+	}
+	if term, ok := idx.TermByID(5); !ok || term.Slug != "news" || term.Taxonomy != "genre" {
+		t.Errorf("TermByID(5) = %v, %v, want the genre term", term, ok)
+	}
+	if _, ok := idx.Term(TaxonomyTag, "news"); ok {
+		t.Errorf(`Term(TaxonomyTag, "news") found a term`)
+	}
+}
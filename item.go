@@ -0,0 +1,204 @@
+package wxr
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/connorkuehl/wxr/phpserial"
+)
+
+// DateLayout is the layout of the dates WordPress exports, such as
+// wp:post_date and wp:comment_date.
+const DateLayout = "2006-01-02 15:04:05"
+
+// zeroDate is what WordPress exports in place of a date that was never
+// set, e.g. the wp:post_date_gmt of a draft.
+const zeroDate = "0000-00-00 00:00:00"
+
+// maxUTCOffset bounds the difference between a local and a GMT date that
+// is believable as a time zone offset.
+const maxUTCOffset = 14 * time.Hour
+
+// ParseDate parses a WordPress date in loc. An empty string or
+// WordPress's "0000-00-00 00:00:00" zero date yields the zero time.Time
+// and no error.
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == zeroDate {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(DateLayout, s, loc)
+}
+
+// PostStatus is the publication status of an item.
+type PostStatus string
+
+// Statuses WordPress assigns to items.
+const (
+	StatusPublish   PostStatus = "publish"
+	StatusFuture    PostStatus = "future"
+	StatusDraft     PostStatus = "draft"
+	StatusPending   PostStatus = "pending"
+	StatusPrivate   PostStatus = "private"
+	StatusTrash     PostStatus = "trash"
+	StatusAutoDraft PostStatus = "auto-draft"
+	StatusInherit   PostStatus = "inherit"
+)
+
+// Known reports whether s is one of the statuses built into WordPress.
+// Plugins may register others.
+func (s PostStatus) Known() bool {
+	switch s {
+	case StatusPublish, StatusFuture, StatusDraft, StatusPending,
+		StatusPrivate, StatusTrash, StatusAutoDraft, StatusInherit:
+		return true
+	}
+	return false
+}
+
+// Status returns the item's publication status.
+func (it Item) Status() PostStatus {
+	return PostStatus(strings.TrimSpace(it.RawStatus))
+}
+
+// IsSticky reports whether the item is pinned to the front page.
+func (it Item) IsSticky() bool {
+	return it.Sticky != 0
+}
+
+// CommentsOpen reports whether the item accepts new comments.
+func (it Item) CommentsOpen() bool {
+	return strings.TrimSpace(it.CommentStatus) == "open"
+}
+
+// PingsOpen reports whether the item accepts pingbacks and trackbacks.
+func (it Item) PingsOpen() bool {
+	return strings.TrimSpace(it.PingStatus) == "open"
+}
+
+// PublishedAt returns when the item was published. See localTime for how
+// the site's local date and the GMT date are reconciled. It returns the
+// zero time.Time if neither date is set or parsable.
+func (it Item) PublishedAt() time.Time {
+	return localTime(it.PostDate, it.PostDateGMT)
+}
+
+// Modified returns when the item was last modified, following the same
+// rules as PublishedAt.
+func (it Item) Modified() time.Time {
+	return localTime(it.PostModified, it.PostModifiedGMT)
+}
+
+// localTime combines a date in the site's local time with its GMT
+// counterpart.
+//
+// WXR doesn't record the site's time zone, so when both dates are set the
+// offset between them is used to place the GMT instant in a fixed zone,
+// which preserves the local wall clock. When only the GMT date is set
+// (or the two disagree by more than any real time zone would) the GMT
+// instant is returned in UTC. Unpublished items usually have a zero GMT
+// date, in which case the local wall clock is returned in UTC since its
+// actual zone is unknown.
+func localTime(local, gmt string) time.Time {
+	l, lerr := ParseDate(local, time.UTC)
+	if lerr != nil {
+		l = time.Time{}
+	}
+	g, gerr := ParseDate(gmt, time.UTC)
+	if gerr != nil {
+		g = time.Time{}
+	}
+
+	switch {
+	case !g.IsZero() && !l.IsZero():
+		offset := l.Sub(g)
+		if offset > maxUTCOffset || offset < -maxUTCOffset {
+			return g
+		}
+		return g.In(time.FixedZone("", int(offset/time.Second)))
+	case !g.IsZero():
+		return g
+	default:
+		return l
+	}
+}
+
+// Meta returns the value of the item's first postmeta with the given key
+// and whether there was one.
+func (it Item) Meta(key string) (string, bool) {
+	for _, m := range it.MetaKVs {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// Decode stores the meta value in v. Values serialized by PHP, which is
+// how WordPress and plugins store arrays and objects, are decoded as
+// described by phpserial.Unmarshal. Anything else decodes as a string.
+func (m PostMeta) Decode(v interface{}) error {
+	return phpserial.MaybeUnmarshal([]byte(m.Value), v)
+}
+
+// Decode stores the meta value in v, see PostMeta.Decode.
+func (m CommentMeta) Decode(v interface{}) error {
+	return phpserial.MaybeUnmarshal([]byte(m.Value), v)
+}
+
+// Decode stores the meta value in v, see PostMeta.Decode.
+func (m TermMeta) Decode(v interface{}) error {
+	return phpserial.MaybeUnmarshal([]byte(m.Value), v)
+}
+
+// The built-in taxonomies.
+const (
+	TaxonomyCategory = "category"
+	TaxonomyTag      = "post_tag"
+)
+
+// PermaLink reports whether the GUID is also the item's address. RSS
+// treats a GUID without an isPermaLink attribute as one.
+func (g GUID) PermaLink() bool {
+	return strings.TrimSpace(g.IsPermaLink) != "false"
+}
+
+// URL returns the GUID parsed as an absolute URL. It reports false if the
+// GUID isn't a permalink or doesn't parse as one.
+//
+// WordPress marks every GUID as not being a permalink, even though most
+// hold the address the item had when it was created; parse Value directly
+// to use those.
+func (g GUID) URL() (*url.URL, bool) {
+	if !g.PermaLink() {
+		return nil, false
+	}
+	u, err := url.Parse(strings.TrimSpace(g.Value))
+	if err != nil || !u.IsAbs() {
+		return nil, false
+	}
+	return u, true
+}
+
+// Categories returns the item's terms in the "category" taxonomy.
+func (it Item) Categories() []ItemCategory {
+	return it.TermsOf(TaxonomyCategory)
+}
+
+// Tags returns the item's terms in the "post_tag" taxonomy.
+func (it Item) Tags() []ItemCategory {
+	return it.TermsOf(TaxonomyTag)
+}
+
+// TermsOf returns the item's terms in the taxonomy named by domain, in the
+// order they appear in the export.
+func (it Item) TermsOf(domain string) []ItemCategory {
+	var terms []ItemCategory
+	for _, c := range it.Category {
+		if c.Domain == domain {
+			terms = append(terms, c)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,159 @@
+package wxr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"valid", "2021-08-07 07:56:40", time.Date(2021, 8, 7, 7, 56, 40, 0, time.UTC), false},
+		{"empty", "", time.Time{}, false},
+		{"zero date", "0000-00-00 00:00:00", time.Time{}, false},
+		{"garbage", "yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.in, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemPublishedAt(t *testing.T) {
+	tests := []struct {
+		name       string
+		local, gmt string
+		want       time.Time
+		wantWall   string
+	}{
+		{"local and gmt", "2021-08-07 07:56:40", "2021-08-07 12:56:40",
+			time.Date(2021, 8, 7, 12, 56, 40, 0, time.UTC), "2021-08-07 07:56:40"},
+		{"gmt only", "", "2021-08-07 12:56:40",
+			time.Date(2021, 8, 7, 12, 56, 40, 0, time.UTC), "2021-08-07 12:56:40"},
+		{"draft with zero gmt", "2021-08-07 07:56:40", "0000-00-00 00:00:00",
+			time.Date(2021, 8, 7, 7, 56, 40, 0, time.UTC), "2021-08-07 07:56:40"},
+		{"implausible offset", "2021-08-07 07:56:40", "2020-11-29 16:29:33",
+			time.Date(2020, 11, 29, 16, 29, 33, 0, time.UTC), "2020-11-29 16:29:33"},
+		{"unparsable local", "bogus", "2021-08-07 12:56:40",
+			time.Date(2021, 8, 7, 12, 56, 40, 0, time.UTC), "2021-08-07 12:56:40"},
+		{"neither", "0000-00-00 00:00:00", "0000-00-00 00:00:00", time.Time{}, "0001-01-01 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := Item{PostDate: tt.local, PostDateGMT: tt.gmt, PostModified: tt.local, PostModifiedGMT: tt.gmt}
+
+			for _, got := range []time.Time{it.PublishedAt(), it.Modified()} {
+				if !got.Equal(tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+				if wall := got.Format(DateLayout); wall != tt.wantWall {
+					t.Errorf("got wall clock %s, want %s", wall, tt.wantWall)
+				}
+			}
+		})
+	}
+}
+
+func TestItemStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        Item
+		status    PostStatus
+		known     bool
+		sticky    bool
+		comments  bool
+		pingsOpen bool
+	}{
+		{"published", Item{RawStatus: "publish", Sticky: 1, CommentStatus: "open", PingStatus: "closed"},
+			StatusPublish, true, true, true, false},
+		{"draft", Item{RawStatus: "draft", CommentStatus: "closed", PingStatus: "open"},
+			StatusDraft, true, false, false, true},
+		{"plugin status", Item{RawStatus: "wc-completed"},
+			PostStatus("wc-completed"), false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.in.Status(); got != tt.status {
+				t.Errorf("Status() = %q, want %q", got, tt.status)
+			}
+			if got := tt.in.Status().Known(); got != tt.known {
+				t.Errorf("Status().Known() = %v, want %v", got, tt.known)
+			}
+			if got := tt.in.IsSticky(); got != tt.sticky {
+				t.Errorf("IsSticky() = %v, want %v", got, tt.sticky)
+			}
+			if got := tt.in.CommentsOpen(); got != tt.comments {
+				t.Errorf("CommentsOpen() = %v, want %v", got, tt.comments)
+			}
+			if got := tt.in.PingsOpen(); got != tt.pingsOpen {
+				t.Errorf("PingsOpen() = %v, want %v", got, tt.pingsOpen)
+			}
+		})
+	}
+}
+
+func TestPostMetaDecode(t *testing.T) {
+	var seo struct {
+		Title    string `php:"title"`
+		NoIndex  bool   `php:"noindex"`
+		Keywords []string
+	}
+	m := PostMeta{
+		Key:   "_seo",
+		Value: `a:3:{s:5:"title";s:5:"Hello";s:7:"noindex";b:1;s:8:"keywords";a:2:{i:0;s:1:"a";i:1;s:1:"b";}}`,
+	}
+	if err := m.Decode(&seo); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if seo.Title != "Hello" || !seo.NoIndex || len(seo.Keywords) != 2 {
+		t.Errorf("Decode got %+v", seo)
+	}
+
+	var plain string
+	if err := (PostMeta{Key: "_edit_last", Value: "1"}).Decode(&plain); err != nil || plain != "1" {
+		t.Errorf("Decode of unserialized value got %q, %v", plain, err)
+	}
+}
+
+func TestGUIDURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   GUID
+		want string
+	}{
+		{"permalink", GUID{IsPermaLink: "true", Value: "https://example.com/?p=9"}, "https://example.com/?p=9"},
+		{"permalink by default", GUID{Value: " https://example.com/hello/ "}, "https://example.com/hello/"},
+		{"not a permalink", GUID{IsPermaLink: "false", Value: "https://example.com/?p=9"}, ""},
+		{"relative", GUID{IsPermaLink: "true", Value: "example.com"}, ""},
+		{"unparsable", GUID{IsPermaLink: "true", Value: "http://[::1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ok := tt.in.URL()
+			if tt.want == "" {
+				if ok {
+					t.Errorf("URL() = %v, want none", u)
+				}
+				return
+			}
+			if !ok || u.String() != tt.want {
+				t.Errorf("URL() = %v, %v, want %s", u, ok, tt.want)
+			}
+		})
+	}
+}
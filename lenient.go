@@ -0,0 +1,301 @@
+package wxr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// A Warning describes a problem that a lenient Decoder recovered from.
+type Warning struct {
+	// Offset is the number of input bytes that had been read when the
+	// problem was found.
+	Offset int64
+
+	Err error
+}
+
+func (w Warning) Error() string {
+	return fmt.Sprintf("offset %d: %v", w.Offset, w.Err)
+}
+
+// A sanitizer feeds a lenient Decoder, cleaning up the input on the way
+// so that encoding/xml doesn't abort on it:
+//
+//   - invalid UTF-8 is replaced with U+FFFD,
+//   - characters that XML doesn't allow, such as most control characters,
+//     are removed,
+//   - a CDATA section that runs into the end tag of the element it was
+//     opened in is closed before that end tag.
+//
+// It implements io.ByteReader so that xml.Decoder reads no further ahead
+// than it needs to, which lets the Decoder resynchronize on the raw input
+// after a syntax error.
+type sanitizer struct {
+	r *bufio.Reader
+
+	// buf holds sanitized bytes that haven't been returned yet.
+	buf []byte
+
+	// n counts the input bytes consumed.
+	n int64
+
+	// last is the most recent byte returned.
+	last byte
+
+	// tag is the name of the most recently opened element, which is
+	// collected while inTag is set.
+	tag   []byte
+	inTag bool
+
+	inCDATA bool
+
+	// closeTag is the end tag of the element the current CDATA section
+	// was opened in, or nil if it isn't known.
+	closeTag []byte
+
+	// removed and replaced record whether illegal characters have been
+	// removed, or invalid UTF-8 replaced, since the most recent start tag,
+	// so that each element is warned about once.
+	removed, replaced bool
+
+	// warn is called with each repair that is made.
+	warn func(Warning)
+}
+
+func newSanitizer(r io.Reader, warn func(Warning)) *sanitizer {
+	return &sanitizer{r: bufio.NewReader(r), warn: warn}
+}
+
+// ReadByte implements io.ByteReader.
+func (s *sanitizer) ReadByte() (byte, error) {
+	for len(s.buf) == 0 {
+		if err := s.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	b := s.buf[0]
+	s.buf = s.buf[1:]
+	s.last = b
+	return b, nil
+}
+
+// Read implements io.Reader.
+func (s *sanitizer) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := s.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		p[n] = b
+		n++
+
+		if len(s.buf) == 0 {
+			break
+		}
+	}
+	return n, nil
+}
+
+// fill sanitizes the next piece of input into buf. It may consume input
+// without adding anything to buf.
+func (s *sanitizer) fill() error {
+	if s.inCDATA {
+		// Most of the input is CDATA, so only look further ahead when
+		// the next byte can start one of the tokens that end it.
+		if next, _ := s.r.Peek(1); len(next) == 1 && (next[0] == cdataClose[0] || next[0] == '<') {
+			if s.peekIs(cdataClose) {
+				s.take(len(cdataClose))
+				s.inCDATA = false
+				return nil
+			}
+			if b, _ := s.r.Peek(len(s.closeTag)); len(s.closeTag) > 0 && bytes.Equal(b, s.closeTag) {
+				s.warn(Warning{s.n, fmt.Errorf("wxr: closed unterminated CDATA section in <%s>", s.tag)})
+				s.buf = append(s.buf, cdataClose...)
+				s.inCDATA = false
+				return nil
+			}
+		} else if n := s.plainRun(); n > 0 {
+			s.take(n)
+			return nil
+		}
+	} else if s.peekIs(cdataOpen) {
+		s.take(len(cdataOpen))
+		s.inCDATA = true
+		s.inTag = false
+		s.closeTag = s.closeTag[:0]
+		if len(s.tag) > 0 {
+			s.closeTag = append(append(append(s.closeTag, "</"...), s.tag...), '>')
+		}
+		return nil
+	}
+
+	r, size, err := s.r.ReadRune()
+	if err != nil {
+		return err
+	}
+	s.n += int64(size)
+
+	if r == utf8.RuneError && size == 1 {
+		// Invalid UTF-8, which ReadRune has already replaced.
+		s.repaired(&s.replaced, "replaced invalid UTF-8")
+	} else if !isXMLChar(r) {
+		s.repaired(&s.removed, "removed illegal characters")
+		return nil
+	}
+
+	if !s.inCDATA {
+		s.track(r)
+	}
+
+	var enc [utf8.UTFMax]byte
+	s.buf = append(s.buf, enc[:utf8.EncodeRune(enc[:], r)]...)
+	return nil
+}
+
+// repaired warns that the repair described by what has been made, unless
+// *done reports that it already has been in the current element.
+func (s *sanitizer) repaired(done *bool, what string) {
+	if *done {
+		return
+	}
+	*done = true
+	s.warn(Warning{s.n, fmt.Errorf("wxr: %s in <%s>", what, s.tag)})
+}
+
+// track follows the element names of start tags outside of CDATA.
+func (s *sanitizer) track(r rune) {
+	if s.inTag {
+		if isNameChar(r) {
+			var enc [utf8.UTFMax]byte
+			s.tag = append(s.tag, enc[:utf8.EncodeRune(enc[:], r)]...)
+			return
+		}
+		s.inTag = false
+	}
+
+	if r == '<' {
+		next, _ := s.r.Peek(1)
+		if len(next) == 1 && isNameChar(rune(next[0])) {
+			s.tag = s.tag[:0]
+			s.inTag = true
+			s.removed, s.replaced = false, false
+		}
+	}
+}
+
+// plainRun returns the length of the buffered input that can be passed
+// through as it is inside a CDATA section: ASCII characters that XML
+// allows, up to the next byte that could start the end of the section.
+func (s *sanitizer) plainRun() int {
+	b, _ := s.r.Peek(s.r.Buffered())
+	for i, c := range b {
+		switch {
+		case c == cdataClose[0] || c == '<' || c >= utf8.RuneSelf:
+			return i
+		case c < 0x20 && c != '\t' && c != '\n' && c != '\r':
+			return i
+		}
+	}
+	return len(b)
+}
+
+func (s *sanitizer) peekIs(tok string) bool {
+	b, _ := s.r.Peek(len(tok))
+	return string(b) == tok
+}
+
+// take copies the next n bytes of input to buf unchanged.
+func (s *sanitizer) take(n int) {
+	b, _ := s.r.Peek(n)
+	s.buf = append(s.buf, b...)
+	s.r.Discard(n)
+	s.n += int64(n)
+}
+
+// skipPast discards input up to and including the next occurrence of tok,
+// and any sanitized bytes that haven't been returned yet. The byte most
+// recently returned counts towards a match, since xml.Decoder may have
+// read one byte it hasn't used yet. It reports whether tok was found.
+func (s *sanitizer) skipPast(tok string) bool {
+	s.buf = s.buf[:0]
+	s.inCDATA = false
+	s.inTag = false
+
+	matched := 0
+	if s.last == tok[0] {
+		matched = 1
+	}
+
+	for matched < len(tok) {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return false
+		}
+		s.n++
+
+		switch {
+		case b == tok[matched]:
+			matched++
+		case b == tok[0]:
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+
+	s.last = tok[len(tok)-1]
+	return true
+}
+
+// isXMLChar reports whether r is allowed by the Char production of the XML
+// specification.
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// isNameChar reports whether r may appear in an element name. It accepts
+// a superset of the XML NameChar production, which is all that's needed
+// to find where a name ends.
+func isNameChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '_' || r == ':' || r == '-' || r == '.' || r >= 0x80
+}
+
+// rootPrefix rewrites the document's <rss> start element and the opening
+// <channel> tag, so that a new xml.Decoder can pick up where a failed one
+// left off with the same namespaces in scope.
+func rootPrefix(root xml.StartElement) []byte {
+	var b bytes.Buffer
+	b.WriteString("<rss")
+	for _, a := range root.Attr {
+		b.WriteByte(' ')
+		switch {
+		case a.Name.Space == "xmlns":
+			b.WriteString("xmlns:" + a.Name.Local)
+		case a.Name.Space == "":
+			b.WriteString(a.Name.Local)
+		default:
+			// Other prefixed attributes can't be written back faithfully,
+			// and don't matter to the Decoder.
+			b.Truncate(b.Len() - 1)
+			continue
+		}
+		b.WriteString(`="`)
+		xml.EscapeText(&b, []byte(a.Value))
+		b.WriteByte('"')
+	}
+	b.WriteString("><channel>")
+	return b.Bytes()
+}
@@ -0,0 +1,113 @@
+package wxr
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const damagedDocument = `<rss version="2.0"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:wp="http://wordpress.org/export/1.2/"
+><channel>
+	<wp:wxr_version>1.2</wp:wxr_version>
+	<item>
+		<title>Control` + "\x01\x08" + ` characters and ` + "\xff" + `bad UTF-8&nbsp;here</title>
+		<wp:post_id>1</wp:post_id>
+	</item>
+	<item>
+		<title>Truncated</title>
+		<content:encoded><![CDATA[<p>cut off</p></content:encoded>
+		<wp:post_id>2</wp:post_id>
+	</item>
+	<item>
+		<title>Bad ID</title>
+		<wp:post_id>two</wp:post_id>
+		<wp:comment><wp:comment_id>x</wp:comment_id></wp:comment>
+	</item>
+	<item>
+		<title>Broken markup</title>
+		<category domain="<">News</category>
+	</item>
+	<item>
+		<title>Survivor</title>
+		<wp:post_id>5</wp:post_id>
+	</item>
+</channel></rss>`
+
+func TestLenientDecoder(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(damagedDocument))
+	dec.Lenient = true
+
+	var items []Item
+	for dec.Next() {
+		items = append(items, dec.Item())
+	}
+	if err := dec.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	var ids []string
+	for _, it := range items {
+		ids = append(ids, strconv.Itoa(it.PostID))
+	}
+	if got, want := strings.Join(ids, ","), "1,2,5"; got != want {
+		t.Fatalf("decoded items %s, want %s", got, want)
+	}
+
+	if want := "Control characters and �bad UTF-8 here"; items[0].Title != want {
+		t.Errorf("Title = %q, want %q", items[0].Title, want)
+	}
+	if want := "<p>cut off</p>"; items[1].Content.Data != want {
+		t.Errorf("Content.Data = %q, want %q", items[1].Content.Data, want)
+	}
+
+	warnings := dec.Warnings()
+	wantWarnings := []string{
+		"removed illegal characters in <title>",
+		"replaced invalid UTF-8 in <title>",
+		"closed unterminated CDATA section in <content:encoded>",
+		`skipped item "Bad ID"`,
+		`skipped item "Broken markup"`,
+	}
+	if len(warnings) != len(wantWarnings) {
+		t.Fatalf("got %d warnings %v, want %d", len(warnings), warnings, len(wantWarnings))
+	}
+	for i, w := range warnings {
+		if !strings.Contains(w.Error(), wantWarnings[i]) {
+			t.Errorf("warning %d = %q, want it to mention %q", i, w.Error(), wantWarnings[i])
+		}
+		if w.Offset <= 0 || w.Offset > int64(len(damagedDocument)) {
+			t.Errorf("warning %d has offset %d outside of the input", i, w.Offset)
+		}
+	}
+
+	var numErr *strconv.NumError
+	if !errors.As(warnings[3].Err, &numErr) {
+		t.Errorf("warning %v doesn't wrap the decoding error", warnings[3])
+	}
+}
+
+func TestStrictDecoderRejectsDamage(t *testing.T) {
+	if _, err := Decode(strings.NewReader(damagedDocument)); err == nil {
+		t.Errorf("Decode succeeded, want an error")
+	}
+}
+
+func TestLenientDecoderTruncatedInput(t *testing.T) {
+	in := damagedDocument[:strings.Index(damagedDocument, "<wp:post_id>5")]
+
+	dec := NewDecoder(strings.NewReader(in))
+	dec.Lenient = true
+	n := 0
+	for dec.Next() {
+		n++
+	}
+	if err := dec.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	if n != 2 {
+		t.Errorf("decoded %d items, want 2", n)
+	}
+}
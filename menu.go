@@ -0,0 +1,209 @@
+package wxr
+
+import (
+	"sort"
+	"strconv"
+)
+
+// PostTypeMenuItem is the PostType of items that are entries in a
+// navigation menu.
+const PostTypeMenuItem = "nav_menu_item"
+
+// TaxonomyNavMenu is the taxonomy of the terms that name navigation menus.
+// Each menu item is filed under the menu it belongs to.
+const TaxonomyNavMenu = "nav_menu"
+
+// Postmeta keys WordPress stores alongside menu items.
+const (
+	metaMenuItemType     = "_menu_item_type"
+	metaMenuItemObject   = "_menu_item_object"
+	metaMenuItemObjectID = "_menu_item_object_id"
+	metaMenuItemParent   = "_menu_item_menu_item_parent"
+	metaMenuItemURL      = "_menu_item_url"
+	metaMenuItemTarget   = "_menu_item_target"
+)
+
+// Kinds of menu item, as stored in _menu_item_type.
+const (
+	MenuItemCustom          = "custom"
+	MenuItemPostType        = "post_type"
+	MenuItemPostTypeArchive = "post_type_archive"
+	MenuItemTaxonomy        = "taxonomy"
+)
+
+// A Menu is a navigation menu.
+type Menu struct {
+	// Slug and Name come from the menu's nav_menu term.
+	Slug string
+	Name string
+
+	// Items are the menu's top-level entries in menu order.
+	Items []*MenuItem
+}
+
+// A MenuItem is an entry in a navigation menu.
+type MenuItem struct {
+	// ID is the post ID of the nav_menu_item.
+	ID int
+
+	// Title is the label of the entry. Entries that weren't given a label
+	// of their own take the title of the post or the name of the term they
+	// link to.
+	Title string
+
+	// Order is the entry's position in the menu.
+	Order int
+
+	// Type is how the entry's target is stored, e.g. MenuItemCustom or
+	// MenuItemPostType, and Object is the post type or taxonomy of the
+	// target.
+	Type   string
+	Object string
+
+	// ObjectID is the ID of the post or term the entry links to.
+	ObjectID int
+
+	// URL is the address of the target: the URL of a custom link or the
+	// link of a post. It is empty for terms and archives, whose
+	// addresses aren't part of the export.
+	URL string
+
+	// NewWindow is set if the link is meant to open in a new window.
+	NewWindow bool
+
+	// Post is the item the entry links to if Type is MenuItemPostType
+	// and the item is in the export.
+	Post *Item
+
+	// Term is the term the entry links to if Type is MenuItemTaxonomy and
+	// the term is in the export.
+	Term *Term
+
+	Parent   *MenuItem
+	Children []*MenuItem
+}
+
+// Menus rebuilds the channel's navigation menus from its nav_menu_item
+// items. The menus are returned in the order their terms appear in the
+// channel, followed by any menus that only their items refer to.
+//
+// An entry whose parent isn't part of the same menu, or whose parent would
+// make the menu circular, is placed at the top level.
+func Menus(c *Channel) []*Menu {
+	idx := c.Index()
+
+	var menus []*Menu
+	bySlug := make(map[string]*Menu)
+	for _, t := range c.Terms {
+		if t.Taxonomy != TaxonomyNavMenu {
+			continue
+		}
+		m := &Menu{Slug: t.Slug, Name: t.Name}
+		menus = append(menus, m)
+		bySlug[m.Slug] = m
+	}
+
+	type entry struct {
+		menu   *Menu
+		item   *MenuItem
+		parent int
+	}
+	var entries []entry
+	byID := make(map[int]entry)
+
+	for i := range c.Items {
+		it := &c.Items[i]
+		if it.PostType != PostTypeMenuItem {
+			continue
+		}
+
+		cats := it.TermsOf(TaxonomyNavMenu)
+		if len(cats) == 0 {
+			continue
+		}
+		m, ok := bySlug[cats[0].NiceName]
+		if !ok {
+			m = &Menu{Slug: cats[0].NiceName, Name: cats[0].Name}
+			menus = append(menus, m)
+			bySlug[m.Slug] = m
+		}
+
+		e := entry{menu: m, item: newMenuItem(it, idx)}
+		e.parent, _ = strconv.Atoi(metaValue(it, metaMenuItemParent))
+		entries = append(entries, e)
+		byID[e.item.ID] = e
+	}
+
+	for _, e := range entries {
+		p, ok := byID[e.parent]
+		if ok && p.menu == e.menu && !p.item.descendsFrom(e.item) {
+			e.item.Parent = p.item
+			p.item.Children = append(p.item.Children, e.item)
+			continue
+		}
+		e.menu.Items = append(e.menu.Items, e.item)
+	}
+
+	for _, m := range menus {
+		sortMenuItems(m.Items)
+	}
+	return menus
+}
+
+// descendsFrom reports whether ancestor is mi or one of mi's ancestors.
+func (mi *MenuItem) descendsFrom(ancestor *MenuItem) bool {
+	for p := mi; p != nil; p = p.Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func newMenuItem(it *Item, idx *Index) *MenuItem {
+	mi := &MenuItem{
+		ID:        it.PostID,
+		Title:     it.Title,
+		Order:     it.MenuOrder,
+		Type:      metaValue(it, metaMenuItemType),
+		Object:    metaValue(it, metaMenuItemObject),
+		NewWindow: metaValue(it, metaMenuItemTarget) == "_blank",
+	}
+	mi.ObjectID, _ = strconv.Atoi(metaValue(it, metaMenuItemObjectID))
+
+	switch mi.Type {
+	case MenuItemCustom:
+		mi.URL = metaValue(it, metaMenuItemURL)
+	case MenuItemPostType:
+		if p, ok := idx.Item(mi.ObjectID); ok {
+			mi.Post = p
+			mi.URL = p.Link
+			if mi.Title == "" {
+				mi.Title = p.Title
+			}
+		}
+	case MenuItemTaxonomy:
+		if t, ok := idx.TermByID(mi.ObjectID); ok {
+			mi.Term = t
+			if mi.Title == "" {
+				mi.Title = t.Name
+			}
+		}
+	}
+
+	return mi
+}
+
+func metaValue(it *Item, key string) string {
+	v, _ := it.Meta(key)
+	return v
+}
+
+func sortMenuItems(items []*MenuItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Order < items[j].Order
+	})
+	for _, mi := range items {
+		sortMenuItems(mi.Children)
+	}
+}
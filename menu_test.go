@@ -0,0 +1,108 @@
+package wxr
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func menuItemFragment(id, order int, menu, typ, object, objectID, parent, url, title string) string {
+	return `<item>
+		<title>` + title + `</title>
+		<wp:post_id>` + strconv.Itoa(id) + `</wp:post_id>
+		<wp:menu_order>` + strconv.Itoa(order) + `</wp:menu_order>
+		<wp:post_type>nav_menu_item</wp:post_type>
+		<category domain="nav_menu" nicename="` + menu + `"><![CDATA[` + menu + `]]></category>
+		<wp:postmeta><wp:meta_key>_menu_item_type</wp:meta_key><wp:meta_value>` + typ + `</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_object</wp:meta_key><wp:meta_value>` + object + `</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_object_id</wp:meta_key><wp:meta_value>` + objectID + `</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_menu_item_parent</wp:meta_key><wp:meta_value>` + parent + `</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_url</wp:meta_key><wp:meta_value>` + url + `</wp:meta_value></wp:postmeta>
+	</item>`
+}
+
+func TestMenus(t *testing.T) {
+	in := wrapChannel(`
+		<wp:term><wp:term_id>2</wp:term_id><wp:term_taxonomy>nav_menu</wp:term_taxonomy><wp:term_slug>main</wp:term_slug><wp:term_name>Main Menu</wp:term_name></wp:term>
+		<wp:category><wp:term_id>7</wp:term_id><wp:category_nicename>news</wp:category_nicename><wp:cat_name>News</wp:cat_name></wp:category>
+		<item>
+			<title>About</title>
+			<link>https://example.com/about/</link>
+			<wp:post_id>10</wp:post_id>
+			<wp:post_type>page</wp:post_type>
+		</item>` +
+		menuItemFragment(21, 3, "main", "custom", "custom", "21", "0", "https://github.com/", "GitHub") +
+		menuItemFragment(22, 1, "main", "post_type", "page", "10", "0", "", "") +
+		menuItemFragment(23, 2, "main", "taxonomy", "category", "7", "22", "", "") +
+		menuItemFragment(24, 1, "main", "custom", "custom", "24", "22", "/team/", "Team") +
+		menuItemFragment(25, 1, "footer", "custom", "custom", "25", "22", "/privacy/", "Privacy"))
+
+	rss, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	menus := Menus(&rss.Channel)
+	if len(menus) != 2 {
+		t.Fatalf("got %d menus, want 2", len(menus))
+	}
+
+	main, footer := menus[0], menus[1]
+	if main.Slug != "main" || main.Name != "Main Menu" {
+		t.Errorf("first menu is %q (%q), want main (Main Menu)", main.Slug, main.Name)
+	}
+	if got := describeMenu(main.Items); got != "About[Team News] GitHub" {
+		t.Errorf("main menu = %q", got)
+	}
+
+	about := main.Items[0]
+	if about.Post == nil || about.Post.PostID != 10 || about.URL != "https://example.com/about/" {
+		t.Errorf("About entry resolved to %+v, %q", about.Post, about.URL)
+	}
+	news := about.Children[1]
+	if news.Term == nil || news.Term.Slug != "news" || news.Parent != about {
+		t.Errorf("News entry resolved to %+v with parent %v", news.Term, news.Parent)
+	}
+	if github := main.Items[1]; github.URL != "https://github.com/" {
+		t.Errorf("GitHub entry URL = %q", github.URL)
+	}
+
+	// The footer's entry names a parent in another menu.
+	if footer.Slug != "footer" || describeMenu(footer.Items) != "Privacy" {
+		t.Errorf("footer menu = %q %q", footer.Slug, describeMenu(footer.Items))
+	}
+}
+
+func describeMenu(items []*MenuItem) string {
+	var s []string
+	for _, mi := range items {
+		d := mi.Title
+		if len(mi.Children) > 0 {
+			d += "[" + describeMenu(mi.Children) + "]"
+		}
+		s = append(s, d)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestMenusCycle(t *testing.T) {
+	in := wrapChannel(
+		menuItemFragment(31, 1, "main", "custom", "custom", "31", "32", "/a/", "A") +
+			menuItemFragment(32, 2, "main", "custom", "custom", "32", "31", "/b/", "B") +
+			menuItemFragment(33, 3, "main", "custom", "custom", "33", "33", "/c/", "C"))
+
+	rss, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	menus := Menus(&rss.Channel)
+	if len(menus) != 1 {
+		t.Fatalf("got %d menus, want 1", len(menus))
+	}
+	// A is filed under B before B's parent is looked at, so B is the one
+	// that would close the cycle.
+	if got := describeMenu(menus[0].Items); got != "B[A] C" {
+		t.Errorf("main menu = %q, want %q", got, "B[A] C")
+	}
+}
@@ -0,0 +1,298 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// metaThumbnailID is the postmeta key of the ID of an item's featured
+// image.
+const metaThumbnailID = "_thumbnail_id"
+
+// A Remap records that Merge gave an item a new post ID because its own
+// was already taken by an item of an earlier document.
+type Remap struct {
+	// Doc is the index of the item's document in the arguments to Merge.
+	Doc int
+
+	From, To int
+}
+
+// Merge combines docs, such as the files WordPress splits a large export
+// into or the exports of several blogs, into one document. The channel
+// metadata of the first document is kept.
+//
+// Authors are de-duplicated by login, and categories, tags and terms by
+// taxonomy and slug; the first definition wins. An item whose post ID is
+// taken by a different item of an earlier document is given an unused
+// ID, and the post_parent, featured images and menu entries that refer to
+// it from its own document are updated to match. The renumbered items are
+// returned alongside the merged document. An item with the same post ID,
+// post type and GUID as an earlier one is a copy of it, e.g. from
+// overlapping exports, and is dropped.
+//
+// The namespaces that the documents declare for plugin elements are all
+// declared on the merged document. A prefix that an earlier document
+// binds to another namespace is renamed, e.g. to "woo2", and the original
+// declaration is copied to the Extra elements whose content uses it.
+//
+// The documents are not modified.
+func Merge(docs ...*RSS) (*RSS, []Remap) {
+	m := merger{
+		authors: make(map[string]bool),
+		terms:   make(map[termKey]int),
+		termIDs: make(map[int]bool),
+		posts:   make(map[int]mergedPost),
+	}
+	for _, doc := range docs {
+		m.reserve(&doc.Channel)
+	}
+
+	merged := &RSS{}
+	if len(docs) > 0 {
+		*merged = *docs[0]
+		merged.Attrs = append([]xml.Attr(nil), docs[0].Attrs...)
+		c := &merged.Channel
+		c.Authors, c.Categories, c.Tags, c.Terms, c.Items = nil, nil, nil, nil, nil
+	}
+
+	for i, doc := range docs {
+		renamed := mergeNamespaces(merged, doc.Attrs)
+		m.channel(&merged.Channel, i, &doc.Channel, renamed)
+	}
+	return merged, m.remaps
+}
+
+// mergedPost is what tells a copy of a kept item from a different item
+// with the same ID.
+type mergedPost struct {
+	postType, guid string
+}
+
+type merger struct {
+	authors map[string]bool
+
+	// terms maps each kept term to its ID, and termIDs holds the IDs in
+	// use by kept terms.
+	terms   map[termKey]int
+	termIDs map[int]bool
+
+	// posts maps the post IDs in use to the item that holds each.
+	posts map[int]mergedPost
+
+	nextPostID, nextTermID int
+	remaps                 []Remap
+}
+
+// reserve makes sure no ID in c is handed out to a renumbered item or
+// term.
+func (m *merger) reserve(c *Channel) {
+	for _, it := range c.Items {
+		if it.PostID >= m.nextPostID {
+			m.nextPostID = it.PostID + 1
+		}
+	}
+	for _, t := range c.builtinTerms() {
+		if t.ID >= m.nextTermID {
+			m.nextTermID = t.ID + 1
+		}
+	}
+	for _, t := range c.Terms {
+		if t.ID >= m.nextTermID {
+			m.nextTermID = t.ID + 1
+		}
+	}
+}
+
+// channel adds the authors, terms and items of c, the channel of the
+// doc'th document, to dst. renamed holds the namespace declarations of
+// the document that don't hold on dst.
+func (m *merger) channel(dst *Channel, doc int, c *Channel, renamed []xml.Attr) {
+	for _, a := range c.Authors {
+		if !m.authors[a.Login] {
+			m.authors[a.Login] = true
+			dst.Authors = append(dst.Authors, a)
+		}
+	}
+
+	// termIDs maps the term IDs of c to the IDs of the kept terms.
+	termIDs := make(map[int]int)
+	for _, cat := range c.Categories {
+		if id, ok := m.term(termIDs, TaxonomyCategory, cat.NiceName, cat.TermID); ok {
+			cat.TermID = id
+			dst.Categories = append(dst.Categories, cat)
+		}
+	}
+	for _, t := range c.Tags {
+		if id, ok := m.term(termIDs, TaxonomyTag, t.Slug, t.TermID); ok {
+			t.TermID = id
+			dst.Tags = append(dst.Tags, t)
+		}
+	}
+	for _, t := range c.Terms {
+		if id, ok := m.term(termIDs, t.Taxonomy, t.Slug, t.ID); ok {
+			t.ID = id
+			dst.Terms = append(dst.Terms, t)
+		}
+	}
+
+	// postIDs maps the post IDs of c to the IDs of the kept items.
+	postIDs := make(map[int]int, len(c.Items))
+	first := len(dst.Items)
+	for _, it := range c.Items {
+		id := it.PostID
+		if prev, ok := m.posts[id]; ok && id != 0 {
+			if prev == (mergedPost{it.PostType, it.GUID.Value}) {
+				if _, ok := postIDs[id]; !ok {
+					postIDs[id] = id
+				}
+				continue
+			}
+			id = m.nextPostID
+			m.nextPostID++
+			m.remaps = append(m.remaps, Remap{Doc: doc, From: it.PostID, To: id})
+		}
+		if _, ok := postIDs[it.PostID]; !ok {
+			postIDs[it.PostID] = id
+		}
+		it.PostID = id
+		dst.Items = append(dst.Items, it)
+		m.posts[id] = mergedPost{it.PostType, it.GUID.Value}
+	}
+
+	// Only references between the items of one document can be followed;
+	// those to items outside it are left alone.
+	for i := first; i < len(dst.Items); i++ {
+		it := &dst.Items[i]
+		if id, ok := postIDs[it.PostParent]; ok && it.PostParent != 0 {
+			it.PostParent = id
+		}
+		remapItemMeta(it, postIDs, termIDs)
+
+		if len(renamed) > 0 && len(it.Extra) > 0 {
+			// Copy before writing so the documents being merged don't change
+			extra := make([]Element, len(it.Extra))
+			for i, e := range it.Extra {
+				// Names are held by namespace, so only prefixes in the
+				// raw content need their declarations.
+				var used []xml.Attr
+				for _, a := range renamed {
+					if strings.Contains(e.InnerXML, a.Name.Local+":") {
+						used = append(used, a)
+					}
+				}
+				if len(used) > 0 {
+					e.Attrs = append([]xml.Attr(nil), e.Attrs...)
+					e.inherit(used)
+				}
+				extra[i] = e
+			}
+			it.Extra = extra
+		}
+	}
+}
+
+// mergeNamespaces declares the namespaces that attrs, the attributes of a
+// document's root, declare on dst as well. A namespace whose prefix dst
+// already binds to another namespace is declared under a new prefix. It
+// returns the declarations of attrs whose prefixes mean something else on
+// dst.
+func mergeNamespaces(dst *RSS, attrs []xml.Attr) []xml.Attr {
+	bound := make(map[string]string)
+	declared := make(map[string]bool)
+	for _, a := range dst.Attrs {
+		if a.Name.Space == "xmlns" {
+			bound[a.Name.Local] = a.Value
+			declared[a.Value] = true
+		}
+	}
+
+	var renamed []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Space != "xmlns" || bound[a.Name.Local] == a.Value {
+			continue
+		}
+
+		prefix := a.Name.Local
+		if _, ok := bound[prefix]; ok {
+			renamed = append(renamed, a)
+			for n := 2; ; n++ {
+				prefix = a.Name.Local + strconv.Itoa(n)
+				if _, ok := bound[prefix]; !ok {
+					break
+				}
+			}
+		}
+		if declared[a.Value] {
+			continue
+		}
+
+		bound[prefix] = a.Value
+		declared[a.Value] = true
+		dst.Attrs = append(dst.Attrs, xml.Attr{Name: xml.Name{Space: "xmlns", Local: prefix}, Value: a.Value})
+	}
+	return renamed
+}
+
+// term returns the ID the term with the given ID in c is kept under, and
+// whether it is a new term rather than one defined by an earlier document.
+func (m *merger) term(ids map[int]int, taxonomy, slug string, id int) (int, bool) {
+	k := termKey{taxonomy, slug}
+	if kept, ok := m.terms[k]; ok {
+		ids[id] = kept
+		return kept, false
+	}
+	newID := id
+	if m.termIDs[id] {
+		newID = m.nextTermID
+		m.nextTermID++
+	}
+	m.terms[k] = newID
+	m.termIDs[newID] = true
+	ids[id] = newID
+	return newID, true
+}
+
+// remapItemMeta updates the postmeta of it that refer to other items or to
+// terms by ID.
+func remapItemMeta(it *Item, postIDs, termIDs map[int]int) {
+	var changed []PostMeta
+	set := func(i int, ids map[int]int) {
+		id, err := strconv.Atoi(it.MetaKVs[i].Value)
+		if err != nil {
+			return
+		}
+		newID, ok := ids[id]
+		if !ok || newID == id {
+			return
+		}
+		// Copy before writing so the documents being merged don't change
+		if changed == nil {
+			changed = append([]PostMeta(nil), it.MetaKVs...)
+			it.MetaKVs = changed
+		}
+		it.MetaKVs[i].Value = strconv.Itoa(newID)
+	}
+
+	for i, kv := range it.MetaKVs {
+		switch kv.Key {
+		case metaThumbnailID:
+			set(i, postIDs)
+		}
+		if it.PostType != PostTypeMenuItem {
+			continue
+		}
+		switch kv.Key {
+		case metaMenuItemParent:
+			set(i, postIDs)
+		case metaMenuItemObjectID:
+			switch metaValue(it, metaMenuItemType) {
+			case MenuItemPostType:
+				set(i, postIDs)
+			case MenuItemTaxonomy:
+				set(i, termIDs)
+			}
+		}
+	}
+}
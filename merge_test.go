@@ -0,0 +1,224 @@
+package wxr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	first := wrapChannel(`
+		<title>First</title>
+		<wp:author><wp:author_id>1</wp:author_id><wp:author_login>alice</wp:author_login></wp:author>
+		<wp:category><wp:term_id>7</wp:term_id><wp:category_nicename>news</wp:category_nicename><wp:cat_name>News</wp:cat_name></wp:category>
+		<wp:term><wp:term_id>2</wp:term_id><wp:term_taxonomy>nav_menu</wp:term_taxonomy><wp:term_slug>main</wp:term_slug><wp:term_name>Main</wp:term_name></wp:term>
+		<item>
+			<title>Hello</title>
+			<guid isPermaLink="false">https://one.example.com/?p=10</guid>
+			<wp:post_id>10</wp:post_id>
+			<wp:post_type>post</wp:post_type>
+		</item>
+		<item>
+			<title>Contact</title>
+			<guid isPermaLink="false">https://one.example.com/?page_id=13</guid>
+			<wp:post_id>13</wp:post_id>
+			<wp:post_type>page</wp:post_type>
+		</item>`)
+
+	second := wrapChannel(`
+		<title>Second</title>
+		<wp:author><wp:author_id>1</wp:author_id><wp:author_login>alice</wp:author_login></wp:author>
+		<wp:author><wp:author_id>2</wp:author_id><wp:author_login>bob</wp:author_login></wp:author>
+		<wp:category><wp:term_id>9</wp:term_id><wp:category_nicename>news</wp:category_nicename><wp:cat_name>News</wp:cat_name></wp:category>
+		<wp:category><wp:term_id>7</wp:term_id><wp:category_nicename>sport</wp:category_nicename><wp:cat_name>Sport</wp:cat_name></wp:category>
+		<item>
+			<title>Hello again</title>
+			<guid isPermaLink="false">https://one.example.com/?p=10</guid>
+			<wp:post_id>10</wp:post_id>
+			<wp:post_type>post</wp:post_type>
+		</item>
+		<item>
+			<title>About</title>
+			<guid isPermaLink="false">https://two.example.com/?page_id=11</guid>
+			<wp:post_id>11</wp:post_id>
+			<wp:post_type>page</wp:post_type>
+		</item>
+		<item>
+			<title>Team</title>
+			<guid isPermaLink="false">https://two.example.com/?page_id=13</guid>
+			<wp:post_id>13</wp:post_id>
+			<wp:post_parent>11</wp:post_parent>
+			<wp:post_type>page</wp:post_type>
+			<wp:postmeta><wp:meta_key>_thumbnail_id</wp:meta_key><wp:meta_value>11</wp:meta_value></wp:postmeta>
+		</item>
+		<item>
+			<title>Child of Team</title>
+			<guid isPermaLink="false">https://two.example.com/?page_id=12</guid>
+			<wp:post_id>12</wp:post_id>
+			<wp:post_parent>13</wp:post_parent>
+			<wp:post_type>page</wp:post_type>
+		</item>` +
+		menuItemFragment(20, 1, "main", "post_type", "page", "13", "0", "", "") +
+		menuItemFragment(21, 1, "main", "taxonomy", "category", "7", "20", "", ""))
+
+	var docs []*RSS
+	for _, in := range []string{first, second} {
+		rss, err := Decode(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		docs = append(docs, rss)
+	}
+
+	merged, remaps := Merge(docs...)
+	c := &merged.Channel
+
+	if c.Title != "First" {
+		t.Errorf("Title = %q, want the first document's", c.Title)
+	}
+
+	var logins []string
+	for _, a := range c.Authors {
+		logins = append(logins, a.Login)
+	}
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(logins, want) {
+		t.Errorf("authors = %v, want %v", logins, want)
+	}
+
+	var cats []string
+	for _, cat := range c.Categories {
+		cats = append(cats, cat.NiceName)
+	}
+	if want := []string{"news", "sport"}; !reflect.DeepEqual(cats, want) {
+		t.Errorf("categories = %v, want %v", cats, want)
+	}
+	// sport's ID was taken by news, so it gets the first unused one.
+	if got := c.Categories[1].TermID; got != 10 {
+		t.Errorf("sport term ID = %d, want 10", got)
+	}
+
+	// The copy of Hello is dropped; Team collides with Contact and is
+	// moved to the first ID unused by either document.
+	wantRemaps := []Remap{{Doc: 1, From: 13, To: 22}}
+	if !reflect.DeepEqual(remaps, wantRemaps) {
+		t.Errorf("remaps = %+v, want %+v", remaps, wantRemaps)
+	}
+
+	idx := c.Index()
+	var titles []string
+	for _, it := range c.Items {
+		titles = append(titles, it.Title)
+	}
+	if want := []string{"Hello", "Contact", "About", "Team", "Child of Team", "", ""}; !reflect.DeepEqual(titles, want) {
+		t.Fatalf("items = %q, want %q", titles, want)
+	}
+
+	team, _ := idx.Item(22)
+	if team.Title != "Team" || team.PostParent != 11 {
+		t.Errorf("item 22 = %q with parent %d, want Team with parent 11", team.Title, team.PostParent)
+	}
+	if child, _ := idx.Item(12); child.PostParent != 22 {
+		t.Errorf("Child of Team parent = %d, want 22", child.PostParent)
+	}
+
+	menus := Menus(c)
+	if len(menus) != 1 || len(menus[0].Items) != 1 {
+		t.Fatalf("got menus %+v, want one with a single top-level entry", menus)
+	}
+	entry := menus[0].Items[0]
+	if entry.Post == nil || entry.Post.Title != "Team" {
+		t.Errorf("menu entry links to %+v, want Team", entry.Post)
+	}
+	if len(entry.Children) != 1 || entry.Children[0].Term == nil || entry.Children[0].Term.Slug != "sport" {
+		t.Errorf("menu entry children = %+v, want a link to sport", entry.Children)
+	}
+
+	if v, _ := docs[1].Channel.Items[4].Meta("_menu_item_object_id"); v != "13" {
+		t.Errorf("Merge modified its input: _menu_item_object_id = %q, was 13", v)
+	}
+}
+
+func TestMergeNone(t *testing.T) {
+	merged, remaps := Merge()
+	if merged == nil || len(merged.Channel.Items) != 0 || remaps != nil {
+		t.Errorf("Merge() = %+v, %v, want an empty document", merged, remaps)
+	}
+}
+
+func TestMergeNamespaces(t *testing.T) {
+	first := strings.Replace(wrapChannel(`
+		<item>
+			<wp:post_id>1</wp:post_id>
+			<jp:views>3</jp:views>
+		</item>`), "<rss ", `<rss xmlns:jp="https://jetpack.example.com/" `, 1)
+
+	second := strings.Replace(wrapChannel(`
+		<item>
+			<wp:post_id>2</wp:post_id>
+			<woo:price sale="true">9.99</woo:price>
+			<jp:stats><jp:rank>1</jp:rank></jp:stats>
+		</item>`), "<rss ", `<rss xmlns:woo="http://woo.example/ns/" xmlns:jp="urn:example:stats" `, 1)
+
+	var docs []*RSS
+	for _, in := range []string{first, second} {
+		rss, err := Decode(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		docs = append(docs, rss)
+	}
+
+	merged, _ := Merge(docs...)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, merged); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	encoded := buf.String()
+
+	got, err := Decode(strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("decoding the merged document failed: %v\n%s", err, encoded)
+	}
+
+	for _, want := range []string{
+		`xmlns:jp="https://jetpack.example.com/"`,
+		`xmlns:woo="http://woo.example/ns/"`,
+		`xmlns:jp2="urn:example:stats"`,
+		`<woo:price sale="true">9.99</woo:price>`,
+	} {
+		if !strings.Contains(encoded, want) {
+			t.Errorf("encoded document is missing %s:\n%s", want, encoded)
+		}
+	}
+
+	wantNames := [][]xml.Name{
+		{{Space: "https://jetpack.example.com/", Local: "views"}},
+		{{Space: "http://woo.example/ns/", Local: "price"}, {Space: "urn:example:stats", Local: "stats"}},
+	}
+	for i, it := range got.Channel.Items {
+		var names []xml.Name
+		for _, e := range it.Extra {
+			names = append(names, e.XMLName)
+		}
+		if !reflect.DeepEqual(names, wantNames[i]) {
+			t.Errorf("item %d extras = %+v, want %+v", i, names, wantNames[i])
+		}
+	}
+
+	// The children of jp:stats still use the prefix the second document
+	// bound, so it must be declared where they can see it.
+	stats := got.Channel.Items[1].Extra[1]
+	if stats.InnerXML != "<jp:rank>1</jp:rank>" {
+		t.Errorf("stats = %q", stats.InnerXML)
+	}
+	if !strings.Contains(encoded, `<jp:stats xmlns:jp="urn:example:stats">`) {
+		t.Errorf("stats doesn't declare its children's namespace:\n%s", encoded)
+	}
+
+	if len(docs[1].Channel.Items[0].Extra[1].Attrs) != 0 {
+		t.Errorf("Merge modified its input: stats attributes = %+v", docs[1].Channel.Items[0].Extra[1].Attrs)
+	}
+}
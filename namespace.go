@@ -0,0 +1,175 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Namespace prefixes used by WordPress E(x)tended RSS documents.
+const (
+	prefixWP      = "wp"
+	prefixExcerpt = "excerpt"
+	prefixContent = "content"
+	prefixDC      = "dc"
+	prefixWFW     = "wfw"
+)
+
+// xmlNamespace is the namespace bound to the reserved xml: prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// wpNamespacePrefix is the common prefix of the wp: namespace URL for
+// every WXR version, e.g. http://wordpress.org/export/1.2/.
+const wpNamespacePrefix = "http://wordpress.org/export/"
+
+// ErrUnsupportedVersion is returned when a document declares a WXR version
+// that this package doesn't know how to map.
+var ErrUnsupportedVersion = errors.New("wxr: unsupported WXR version")
+
+// Namespaces holds the namespace URLs that a WXR document binds to its
+// wp:, excerpt:, content:, dc: and wfw: prefixes.
+type Namespaces struct {
+	Version string
+	WP      string
+	Excerpt string
+	Content string
+	DC      string
+	WFW     string
+}
+
+// versions maps each supported WXR version to the namespaces WordPress
+// declares when exporting it.
+var versions = map[string]Namespaces{
+	"1.0": {
+		Version: "1.0",
+		WP:      "http://wordpress.org/export/1.0/",
+		Excerpt: "http://wordpress.org/export/1.0/excerpt/",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		WFW:     "http://wellformedweb.org/CommentAPI/",
+	},
+	"1.1": {
+		Version: "1.1",
+		WP:      "http://wordpress.org/export/1.1/",
+		Excerpt: "http://wordpress.org/export/1.1/excerpt/",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		WFW:     "http://wellformedweb.org/CommentAPI/",
+	},
+	"1.2": {
+		Version: "1.2",
+		WP:      "http://wordpress.org/export/1.2/",
+		Excerpt: "http://wordpress.org/export/1.2/excerpt/",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		WFW:     "http://wellformedweb.org/CommentAPI/",
+	},
+}
+
+// DefaultVersion is the WXR version written when a document doesn't
+// specify one.
+const DefaultVersion = "1.2"
+
+// NamespacesFor returns the namespaces used by the given WXR version.
+func NamespacesFor(version string) (Namespaces, error) {
+	ns, ok := versions[version]
+	if !ok {
+		return Namespaces{}, fmt.Errorf("%w %q (supported versions are 1.0, 1.1 and 1.2)", ErrUnsupportedVersion, version)
+	}
+	return ns, nil
+}
+
+// Namespaces resolves the namespaces declared on the document's root
+// element. The WXR version is taken from the wp: namespace URL, or from
+// the channel's wxr_version if the root doesn't declare one. Any
+// namespace the root leaves undeclared falls back to the version's
+// default.
+func (r *RSS) Namespaces() (Namespaces, error) {
+	version := r.Channel.WxrVersion
+	if r.Wp != "" {
+		v, err := versionFromURL(r.Wp)
+		if err != nil {
+			return Namespaces{}, err
+		}
+		version = v
+	}
+	if version == "" {
+		version = DefaultVersion
+	}
+
+	ns, err := NamespacesFor(version)
+	if err != nil {
+		return Namespaces{}, err
+	}
+
+	ns.WP = orDefault(r.Wp, ns.WP)
+	ns.Excerpt = orDefault(r.Excerpt, ns.Excerpt)
+	ns.Content = orDefault(r.Content, ns.Content)
+	ns.DC = orDefault(r.Dc, ns.DC)
+	ns.WFW = orDefault(r.Wfw, ns.WFW)
+	return ns, nil
+}
+
+// versionFromURL extracts the WXR version from a wp: namespace URL such
+// as http://wordpress.org/export/1.2/.
+func versionFromURL(u string) (string, error) {
+	if !strings.HasPrefix(u, wpNamespacePrefix) {
+		return "", fmt.Errorf("%w: unrecognized wp namespace %q", ErrUnsupportedVersion, u)
+	}
+
+	v := strings.TrimSuffix(strings.TrimPrefix(u, wpNamespacePrefix), "/")
+	if _, err := NamespacesFor(v); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// url returns the namespace URL bound to prefix.
+func (ns *Namespaces) url(prefix string) string {
+	switch prefix {
+	case prefixWP:
+		return ns.WP
+	case prefixExcerpt:
+		return ns.Excerpt
+	case prefixContent:
+		return ns.Content
+	case prefixDC:
+		return ns.DC
+	case prefixWFW:
+		return ns.WFW
+	}
+	return ""
+}
+
+// is reports whether name refers to the element prefix:local. An empty
+// prefix refers to the un-namespaced RSS elements.
+//
+// Elements without a namespace and elements whose prefix was never
+// declared (as in a bare fragment) are matched by local name alone. A nil
+// ns accepts the namespace URL of any supported WXR version, which is how
+// fragments are decoded with xml.Unmarshal.
+func (ns *Namespaces) is(name xml.Name, prefix, local string) bool {
+	if name.Local != local {
+		return false
+	}
+	if name.Space == "" {
+		return true
+	}
+	if prefix == "" {
+		return false
+	}
+	if name.Space == prefix {
+		return true
+	}
+
+	if ns != nil {
+		return name.Space == ns.url(prefix)
+	}
+	for _, v := range versions {
+		if name.Space == v.url(prefix) {
+			return true
+		}
+	}
+	return false
+}
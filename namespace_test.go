@@ -0,0 +1,147 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func versionedDocument(version string) string {
+	return fmt.Sprintf(`<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/%[1]s/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/%[1]s/"
+	xmlns:plugin="https://plugin.example.com/"
+>
+<channel>
+	<wp:wxr_version>%[1]s</wp:wxr_version>
+	<item>
+		<title>Post</title>
+		<dc:creator>author</dc:creator>
+		<content:encoded><![CDATA[content]]></content:encoded>
+		<excerpt:encoded><![CDATA[excerpt]]></excerpt:encoded>
+		<wp:post_id>7</wp:post_id>
+		<wp:status>publish</wp:status>
+		<plugin:status>should be ignored</plugin:status>
+		<plugin:post_id>99</plugin:post_id>
+	</item>
+</channel>
+</rss>`, version)
+}
+
+func TestDecodeVersions(t *testing.T) {
+	for _, version := range []string{"1.0", "1.1", "1.2"} {
+		t.Run(version, func(t *testing.T) {
+			in := versionedDocument(version)
+
+			streamed, err := Decode(strings.NewReader(in))
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			var unmarshaled RSS
+			if err := xml.Unmarshal([]byte(in), &unmarshaled); err != nil {
+				t.Fatalf("xml.Unmarshal failed: %v", err)
+			}
+
+			for _, rss := range []*RSS{streamed, &unmarshaled} {
+				if len(rss.Channel.Items) != 1 {
+					t.Fatalf("got %d items, want 1", len(rss.Channel.Items))
+				}
+
+				it := rss.Channel.Items[0]
+				if it.Creator != "author" {
+					t.Errorf("Creator = %q, want %q", it.Creator, "author")
+				}
+				if it.Content.Data != "content" {
+					t.Errorf("Content.Data = %q, want %q", it.Content.Data, "content")
+				}
+				if it.Excerpt.Data != "excerpt" {
+					t.Errorf("Excerpt.Data = %q, want %q", it.Excerpt.Data, "excerpt")
+				}
+				if it.PostID != 7 {
+					t.Errorf("PostID = %d, want 7", it.PostID)
+				}
+				if it.RawStatus != "publish" {
+					t.Errorf("RawStatus = %q, want %q", it.RawStatus, "publish")
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeMismatchedExcerptNamespace(t *testing.T) {
+	// A WXR 1.1 excerpt in a document that declares 1.2 belongs to some
+	// other vocabulary and must not be mistaken for the excerpt.
+	in := `<rss xmlns:wp="http://wordpress.org/export/1.2/" xmlns:old="http://wordpress.org/export/1.1/excerpt/">
+	<channel><item><old:encoded>nope</old:encoded></item></channel></rss>`
+
+	rss, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got := rss.Channel.Items[0].Excerpt.Data; got != "" {
+		t.Errorf("Excerpt.Data = %q, want empty", got)
+	}
+}
+
+func TestDecodeUnsupportedVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"namespace", `<rss xmlns:wp="http://wordpress.org/export/9.9/"><channel></channel></rss>`},
+		{"foreign namespace", `<rss xmlns:wp="https://example.com/wp/"><channel></channel></rss>`},
+		{"wxr_version", `<rss><channel><wp:wxr_version>0.9</wp:wxr_version></channel></rss>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.in))
+			if !errors.Is(err, ErrUnsupportedVersion) {
+				t.Errorf("Decode error = %v, want ErrUnsupportedVersion", err)
+			}
+
+			var rss RSS
+			err = xml.Unmarshal([]byte(tt.in), &rss)
+			if !errors.Is(err, ErrUnsupportedVersion) {
+				t.Errorf("xml.Unmarshal error = %v, want ErrUnsupportedVersion", err)
+			}
+		})
+	}
+}
+
+func TestRSSNamespaces(t *testing.T) {
+	tests := []struct {
+		name string
+		in   RSS
+		want Namespaces
+	}{
+		{"empty defaults to 1.2", RSS{}, versions["1.2"]},
+		{"version from channel", RSS{Channel: Channel{WxrVersion: "1.1"}}, versions["1.1"]},
+		{"version from namespace", RSS{Wp: "http://wordpress.org/export/1.0/"}, versions["1.0"]},
+		{"declared namespace overrides default", RSS{Wp: "http://wordpress.org/export/1.2/", Dc: "urn:dc"}, Namespaces{
+			Version: "1.2",
+			WP:      "http://wordpress.org/export/1.2/",
+			Excerpt: "http://wordpress.org/export/1.2/excerpt/",
+			Content: "http://purl.org/rss/1.0/modules/content/",
+			DC:      "urn:dc",
+			WFW:     "http://wellformedweb.org/CommentAPI/",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.in.Namespaces()
+			if err != nil {
+				t.Fatalf("Namespaces failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Namespaces got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
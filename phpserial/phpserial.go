@@ -0,0 +1,704 @@
+// Package phpserial decodes values written by PHP's serialize(), which
+// WordPress uses to store arrays and objects in postmeta, termmeta and
+// options.
+//
+// Values decode into Go as follows:
+//
+//	null    nil
+//	bool    bool
+//	int     int64
+//	float   float64
+//	string  string
+//	array   Array
+//	object  *Object
+//
+// Unmarshal can also decode into structs, maps, slices and scalars, much
+// like encoding/json.
+package phpserial
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A KeyValue is one entry of a PHP array. Its Key is an int64 or a
+// string.
+type KeyValue struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// An Array is a PHP array, which is an ordered map whose keys are
+// integers or strings.
+type Array []KeyValue
+
+// Get returns the value stored under key. Integer keys are matched by
+// their decimal representation, which is how PHP itself treats numeric
+// string keys.
+func (a Array) Get(key string) (interface{}, bool) {
+	for _, kv := range a {
+		if keyString(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Map returns the array as a map keyed by the string form of each key.
+func (a Array) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(a))
+	for _, kv := range a {
+		m[keyString(kv.Key)] = kv.Value
+	}
+	return m
+}
+
+// List returns the array's values in order, discarding the keys.
+func (a Array) List() []interface{} {
+	l := make([]interface{}, len(a))
+	for i, kv := range a {
+		l[i] = kv.Value
+	}
+	return l
+}
+
+// An Object is an instance of a PHP class.
+type Object struct {
+	Class string
+
+	// Properties holds the object's properties. The names of private and
+	// protected properties are stripped of the markers PHP prefixes them
+	// with.
+	Properties Array
+
+	// Raw holds the payload of an object that implements PHP's
+	// Serializable interface, whose format is defined by the class. Its
+	// Properties are empty.
+	Raw string
+}
+
+func keyString(k interface{}) string {
+	switch k := k.(type) {
+	case string:
+		return k
+	case int64:
+		return strconv.FormatInt(k, 10)
+	}
+	return fmt.Sprint(k)
+}
+
+// A SyntaxError describes malformed serialized data.
+type SyntaxError struct {
+	msg    string
+	Offset int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("phpserial: %s at offset %d", e.msg, e.Offset)
+}
+
+// An UnmarshalTypeError describes a PHP value that can't be stored in a Go
+// value of a specific type.
+type UnmarshalTypeError struct {
+	Value string
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "phpserial: cannot unmarshal PHP " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to
+// Unmarshal.
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "phpserial: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Ptr {
+		return "phpserial: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "phpserial: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// IsSerialized reports whether s looks like the output of PHP's
+// serialize(), following the same checks as WordPress's is_serialized().
+func IsSerialized(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "N;" {
+		return true
+	}
+	if len(s) < 4 || s[1] != ':' {
+		return false
+	}
+
+	last := s[len(s)-1]
+	switch s[0] {
+	case 's':
+		return last == ';' && strings.Contains(s, `"`)
+	case 'a', 'O', 'C':
+		return last == '}'
+	case 'b', 'i', 'd':
+		return last == ';'
+	}
+	return false
+}
+
+// Unmarshal decodes the serialized PHP value in data and stores the
+// result in the value pointed to by v.
+//
+// Structs are decoded from arrays and objects by matching keys against
+// the field's `php:"name"` tag, or its name (case insensitively) if it has
+// no tag. A tag of "-" skips the field. Maps with string or integer keys
+// and slices decode from arrays; slices take the array's values in order.
+// Integers and floats also decode from numeric strings, since WordPress
+// stores numbers both ways.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	val, err := parse(string(data))
+	if err != nil {
+		return err
+	}
+	return assign(val, rv.Elem())
+}
+
+// MaybeUnmarshal is like Unmarshal, except that data which isn't
+// serialized is decoded as a PHP string, the same way WordPress's
+// maybe_unserialize() treats it.
+func MaybeUnmarshal(data []byte, v interface{}) error {
+	if s := strings.TrimSpace(string(data)); IsSerialized(s) {
+		return Unmarshal([]byte(s), v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	return assign(string(data), rv.Elem())
+}
+
+func parse(s string) (interface{}, error) {
+	p := parser{s: s}
+	v, err := p.value(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected trailing data")
+	}
+	return v, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+
+	// vars holds every value decoded so far, in the order PHP numbers them
+	// for back references.
+	vars []interface{}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{msg: fmt.Sprintf(format, args...), Offset: p.pos}
+}
+
+// expect consumes the literal tok.
+func (p *parser) expect(tok string) error {
+	if !strings.HasPrefix(p.s[p.pos:], tok) {
+		return p.errorf("expected %q", tok)
+	}
+	p.pos += len(tok)
+	return nil
+}
+
+// until consumes and returns everything up to the next delim, which is
+// consumed as well.
+func (p *parser) until(delim byte) (string, error) {
+	i := strings.IndexByte(p.s[p.pos:], delim)
+	if i < 0 {
+		return "", p.errorf("expected %q", delim)
+	}
+	tok := p.s[p.pos : p.pos+i]
+	p.pos += i + 1
+	return tok, nil
+}
+
+func (p *parser) int(delim byte) (int64, error) {
+	start := p.pos
+	tok, err := p.until(delim)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(tok, 10, 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid integer %q", tok)
+	}
+	return n, nil
+}
+
+func (p *parser) length(delim byte) (int, error) {
+	start := p.pos
+	n, err := p.int(delim)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 || n > int64(len(p.s)) {
+		p.pos = start
+		return 0, p.errorf("invalid length %d", n)
+	}
+	return int(n), nil
+}
+
+// quoted consumes a length-prefixed, double-quoted string such as the
+// 5:"hello" of s:5:"hello";.
+func (p *parser) quoted() (string, error) {
+	n, err := p.length(':')
+	if err != nil {
+		return "", err
+	}
+	if err := p.expect(`"`); err != nil {
+		return "", err
+	}
+	if p.pos+n > len(p.s) {
+		return "", p.errorf("string of length %d overruns input", n)
+	}
+	str := p.s[p.pos : p.pos+n]
+	p.pos += n
+	return str, p.expect(`"`)
+}
+
+// value decodes the next value. Keys aren't numbered for back
+// references, so isKey must be set when decoding one.
+func (p *parser) value(isKey bool) (interface{}, error) {
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	kind := p.s[p.pos]
+	p.pos++
+
+	if kind == 'N' {
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		return p.push(nil, isKey), nil
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case 'b':
+		start := p.pos
+		n, err := p.int(';')
+		if err != nil {
+			return nil, err
+		}
+		if n != 0 && n != 1 {
+			p.pos = start
+			return nil, p.errorf("invalid boolean %d", n)
+		}
+		return p.push(n == 1, isKey), nil
+	case 'i':
+		n, err := p.int(';')
+		if err != nil {
+			return nil, err
+		}
+		return p.push(n, isKey), nil
+	case 'd':
+		start := p.pos
+		tok, err := p.until(';')
+		if err != nil {
+			return nil, err
+		}
+		f, err := parseFloat(tok)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid float %q", tok)
+		}
+		return p.push(f, isKey), nil
+	case 's':
+		str, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		return p.push(str, isKey), nil
+	case 'a':
+		if isKey {
+			return nil, p.errorf("array used as a key")
+		}
+		idx := len(p.vars)
+		p.push(nil, false)
+		arr, err := p.entries(false)
+		if err != nil {
+			return nil, err
+		}
+		p.vars[idx] = arr
+		return arr, nil
+	case 'O':
+		if isKey {
+			return nil, p.errorf("object used as a key")
+		}
+		class, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		obj := &Object{Class: class}
+		p.push(obj, false)
+		props, err := p.entries(true)
+		if err != nil {
+			return nil, err
+		}
+		obj.Properties = props
+		return obj, nil
+	case 'C':
+		if isKey {
+			return nil, p.errorf("object used as a key")
+		}
+		class, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		n, err := p.length(':')
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if p.pos+n > len(p.s) {
+			return nil, p.errorf("payload of length %d overruns input", n)
+		}
+		obj := &Object{Class: class, Raw: p.s[p.pos : p.pos+n]}
+		p.pos += n
+		if err := p.expect("}"); err != nil {
+			return nil, err
+		}
+		return p.push(obj, false), nil
+	case 'r', 'R':
+		if isKey {
+			return nil, p.errorf("reference used as a key")
+		}
+		start := p.pos
+		n, err := p.int(';')
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > int64(len(p.vars)) {
+			p.pos = start
+			return nil, p.errorf("reference to undefined value %d", n)
+		}
+		v := p.vars[n-1]
+		if kind == 'r' {
+			p.push(v, false)
+		}
+		return v, nil
+	}
+
+	p.pos--
+	return nil, p.errorf("unknown type %q", kind)
+}
+
+// push records v for back references unless it is a key, and returns it.
+func (p *parser) push(v interface{}, isKey bool) interface{} {
+	if !isKey {
+		p.vars = append(p.vars, v)
+	}
+	return v
+}
+
+// entries decodes the n:{key;value...} body of an array or object.
+func (p *parser) entries(isObject bool) (Array, error) {
+	n, err := p.length(':')
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	arr := make(Array, 0, n)
+	for i := 0; i < n; i++ {
+		start := p.pos
+		k, err := p.value(true)
+		if err != nil {
+			return nil, err
+		}
+		switch key := k.(type) {
+		case int64:
+		case string:
+			if isObject {
+				k = propertyName(key)
+			}
+		default:
+			p.pos = start
+			return nil, p.errorf("invalid key type %T", k)
+		}
+
+		v, err := p.value(false)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, KeyValue{Key: k, Value: v})
+	}
+
+	return arr, p.expect("}")
+}
+
+// propertyName strips the "\x00Class\x00" and "\x00*\x00" markers PHP
+// prefixes private and protected property names with.
+func propertyName(name string) string {
+	if len(name) == 0 || name[0] != 0 {
+		return name
+	}
+	if i := strings.IndexByte(name[1:], 0); i >= 0 {
+		return name[i+2:]
+	}
+	return name
+}
+
+func parseFloat(s string) (float64, error) {
+	switch s {
+	case "INF":
+		s = "+Inf"
+	case "-INF":
+		s = "-Inf"
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// kind describes v the way PHP would, for error messages.
+func kind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case Array:
+		return "array"
+	case *Object:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// assign stores the decoded PHP value val in rv.
+func assign(val interface{}, rv reflect.Value) error {
+	if rv.Kind() == reflect.Ptr {
+		if val == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return assign(val, rv.Elem())
+	}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		if val == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(val))
+		}
+		return nil
+	}
+
+	if val == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	mismatch := &UnmarshalTypeError{Value: kind(val), Type: rv.Type()}
+
+	switch rv.Kind() {
+	case reflect.String:
+		switch v := val.(type) {
+		case string:
+			rv.SetString(v)
+		case int64:
+			rv.SetString(strconv.FormatInt(v, 10))
+		default:
+			return mismatch
+		}
+	case reflect.Bool:
+		switch v := val.(type) {
+		case bool:
+			rv.SetBool(v)
+		case int64:
+			rv.SetBool(v != 0)
+		default:
+			return mismatch
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := val.(type) {
+		case int64:
+			n = v
+		case string:
+			var err error
+			if n, err = strconv.ParseInt(strings.TrimSpace(v), 10, 64); err != nil {
+				return mismatch
+			}
+		default:
+			return mismatch
+		}
+		if rv.OverflowInt(n) {
+			return mismatch
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch v := val.(type) {
+		case int64:
+			if v < 0 {
+				return mismatch
+			}
+			n = uint64(v)
+		case string:
+			var err error
+			if n, err = strconv.ParseUint(strings.TrimSpace(v), 10, 64); err != nil {
+				return mismatch
+			}
+		default:
+			return mismatch
+		}
+		if rv.OverflowUint(n) {
+			return mismatch
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := val.(type) {
+		case float64:
+			f = v
+		case int64:
+			f = float64(v)
+		case string:
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+				return mismatch
+			}
+		default:
+			return mismatch
+		}
+		rv.SetFloat(f)
+	case reflect.Struct:
+		arr, ok := entriesOf(val)
+		if !ok {
+			return mismatch
+		}
+		return assignStruct(arr, rv)
+	case reflect.Map:
+		arr, ok := entriesOf(val)
+		if !ok {
+			return mismatch
+		}
+		return assignMap(arr, rv)
+	case reflect.Slice:
+		arr, ok := entriesOf(val)
+		if !ok {
+			return mismatch
+		}
+		s := reflect.MakeSlice(rv.Type(), len(arr), len(arr))
+		for i, kv := range arr {
+			if err := assign(kv.Value, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	default:
+		return mismatch
+	}
+
+	return nil
+}
+
+// entriesOf returns the entries of an array or the properties of an
+// object.
+func entriesOf(val interface{}) (Array, bool) {
+	switch v := val.(type) {
+	case Array:
+		return v, true
+	case *Object:
+		return v.Properties, v.Raw == ""
+	}
+	return nil, false
+}
+
+func assignStruct(arr Array, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Name
+		exact := false
+		if tag := f.Tag.Get("php"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+			exact = true
+		}
+
+		for _, kv := range arr {
+			k := keyString(kv.Key)
+			if k == name || (!exact && strings.EqualFold(k, name)) {
+				if err := assign(kv.Value, rv.Field(i)); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func assignMap(arr Array, rv reflect.Value) error {
+	t := rv.Type()
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(t, len(arr)))
+	}
+
+	for _, kv := range arr {
+		key := reflect.New(t.Key()).Elem()
+		if err := assign(kv.Key, key); err != nil {
+			return err
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if err := assign(kv.Value, elem); err != nil {
+			return err
+		}
+		rv.SetMapIndex(key, elem)
+	}
+	return nil
+}
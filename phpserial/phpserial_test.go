@@ -0,0 +1,269 @@
+package phpserial
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalInterface(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want interface{}
+	}{
+		{"null", `N;`, nil},
+		{"true", `b:1;`, true},
+		{"false", `b:0;`, false},
+		{"int", `i:-42;`, int64(-42)},
+		{"float", `d:0.5;`, 0.5},
+		{"float exponent", `d:1.0E+25;`, 1.0e25},
+		{"string", `s:5:"hello";`, "hello"},
+		{"string with quotes and semicolons", `s:6:"a";"b;";`, `a";"b;`},
+		{"multibyte string counts bytes", `s:6:"héllo";`, "héllo"},
+		{"empty array", `a:0:{}`, Array{}},
+		{"list", `a:2:{i:0;s:1:"a";i:1;s:1:"b";}`, Array{
+			{Key: int64(0), Value: "a"},
+			{Key: int64(1), Value: "b"},
+		}},
+		{"nested array", `a:1:{s:5:"sizes";a:1:{s:5:"thumb";i:150;}}`, Array{
+			{Key: "sizes", Value: Array{{Key: "thumb", Value: int64(150)}}},
+		}},
+		{"object", `O:8:"stdClass":2:{s:4:"name";s:3:"Bob";s:3:"age";i:30;}`, &Object{
+			Class: "stdClass",
+			Properties: Array{
+				{Key: "name", Value: "Bob"},
+				{Key: "age", Value: int64(30)},
+			},
+		}},
+		{"object visibility", "O:3:\"Foo\":2:{s:8:\"\x00Foo\x00bar\";i:1;s:6:\"\x00*\x00baz\";i:2;}", &Object{
+			Class: "Foo",
+			Properties: Array{
+				{Key: "bar", Value: int64(1)},
+				{Key: "baz", Value: int64(2)},
+			},
+		}},
+		{"custom serialized object", `C:11:"ArrayObject":21:{x:i:0;a:0:{};m:a:0:{}}`, &Object{
+			Class: "ArrayObject",
+			Raw:   "x:i:0;a:0:{};m:a:0:{}",
+		}},
+		{"value reference", `a:2:{i:0;s:1:"x";i:1;r:2;}`, Array{
+			{Key: int64(0), Value: "x"},
+			{Key: int64(1), Value: "x"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got interface{}
+			if err := Unmarshal([]byte(tt.in), &got); err != nil {
+				t.Fatalf("Unmarshal(%q) failed: %v", tt.in, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal(%q) got %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInfinity(t *testing.T) {
+	var f float64
+	if err := Unmarshal([]byte(`d:-INF;`), &f); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !math.IsInf(f, -1) {
+		t.Errorf("Unmarshal got %v, want -Inf", f)
+	}
+}
+
+func TestUnmarshalSyntaxError(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ``},
+		{"unknown type", `x:1;`},
+		{"missing semicolon", `i:1`},
+		{"bad integer", `i:one;`},
+		{"bad boolean", `b:2;`},
+		{"string too long", `s:10:"short";`},
+		{"string too short", `s:2:"short";`},
+		{"truncated array", `a:2:{i:0;i:1;}`},
+		{"array key", `a:1:{a:0:{}i:1;}`},
+		{"dangling reference", `a:1:{i:0;r:5;}`},
+		{"trailing data", `i:1;i:2;`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got interface{}
+			err := Unmarshal([]byte(tt.in), &got)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("Unmarshal(%q) error = %v, want a *SyntaxError", tt.in, err)
+			}
+		})
+	}
+}
+
+const attachmentMetadata = `a:4:{s:5:"width";i:1200;s:6:"height";s:3:"800";s:4:"file";s:17:"2021/08/photo.jpg";` +
+	`s:5:"sizes";a:1:{s:9:"thumbnail";a:3:{s:4:"file";s:17:"photo-150x150.jpg";s:5:"width";i:150;s:9:"mime-type";s:10:"image/jpeg";}}}`
+
+func TestUnmarshalStruct(t *testing.T) {
+	type size struct {
+		File     string
+		Width    int
+		MIMEType string `php:"mime-type"`
+	}
+	type metadata struct {
+		Width   int
+		Height  int
+		File    string `php:"file"`
+		Ignored string `php:"-"`
+		Sizes   map[string]size
+	}
+
+	var got metadata
+	if err := Unmarshal([]byte(attachmentMetadata), &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	want := metadata{
+		Width:  1200,
+		Height: 800,
+		File:   "2021/08/photo.jpg",
+		Sizes: map[string]size{
+			"thumbnail": {File: "photo-150x150.jpg", Width: 150, MIMEType: "image/jpeg"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal got %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalCollections(t *testing.T) {
+	var list []string
+	if err := Unmarshal([]byte(`a:2:{i:0;s:1:"a";i:1;s:1:"b";}`), &list); err != nil {
+		t.Fatalf("Unmarshal into slice failed: %v", err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(list, want) {
+		t.Errorf("Unmarshal into slice got %v, want %v", list, want)
+	}
+
+	var byID map[int]bool
+	if err := Unmarshal([]byte(`a:2:{i:3;b:1;s:1:"7";b:0;}`), &byID); err != nil {
+		t.Fatalf("Unmarshal into map failed: %v", err)
+	}
+	if want := map[int]bool{3: true, 7: false}; !reflect.DeepEqual(byID, want) {
+		t.Errorf("Unmarshal into map got %v, want %v", byID, want)
+	}
+
+	var obj struct{ Name string }
+	if err := Unmarshal([]byte(`O:8:"stdClass":1:{s:4:"name";s:3:"Bob";}`), &obj); err != nil {
+		t.Fatalf("Unmarshal object into struct failed: %v", err)
+	}
+	if obj.Name != "Bob" {
+		t.Errorf("Unmarshal object into struct got %+v, want Bob", obj)
+	}
+
+	var ptr *int
+	if err := Unmarshal([]byte(`i:5;`), &ptr); err != nil || ptr == nil || *ptr != 5 {
+		t.Errorf("Unmarshal into pointer got %v, %v, want 5", ptr, err)
+	}
+}
+
+func TestUnmarshalTypeError(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		v    interface{}
+	}{
+		{"string into int", `s:3:"abc";`, new(int)},
+		{"array into string", `a:0:{}`, new(string)},
+		{"int into slice", `i:1;`, new([]int)},
+		{"overflow", `i:300;`, new(int8)},
+		{"negative into uint", `i:-1;`, new(uint)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.in), tt.v)
+			var typeErr *UnmarshalTypeError
+			if !errors.As(err, &typeErr) {
+				t.Errorf("Unmarshal error = %v, want an *UnmarshalTypeError", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalInvalidArgument(t *testing.T) {
+	var n int
+	for _, v := range []interface{}{nil, n, (*int)(nil)} {
+		var invalid *InvalidUnmarshalError
+		if err := Unmarshal([]byte(`i:1;`), v); !errors.As(err, &invalid) {
+			t.Errorf("Unmarshal(%T) error = %v, want an *InvalidUnmarshalError", v, err)
+		}
+	}
+}
+
+func TestMaybeUnmarshal(t *testing.T) {
+	var s string
+	if err := MaybeUnmarshal([]byte("plain text"), &s); err != nil || s != "plain text" {
+		t.Errorf("MaybeUnmarshal of plain text got %q, %v", s, err)
+	}
+
+	var n int
+	if err := MaybeUnmarshal([]byte("i:3;"), &n); err != nil || n != 3 {
+		t.Errorf("MaybeUnmarshal of serialized int got %d, %v", n, err)
+	}
+}
+
+func TestIsSerialized(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{`N;`, true},
+		{`b:1;`, true},
+		{`i:12;`, true},
+		{`d:0.5;`, true},
+		{`s:1:"a";`, true},
+		{`a:0:{}`, true},
+		{`O:8:"stdClass":0:{}`, true},
+		{`  a:0:{}  `, true},
+		{`hello`, false},
+		{`12`, false},
+		{`a:0:{`, false},
+		{``, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSerialized(tt.in); got != tt.want {
+			t.Errorf("IsSerialized(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestArray(t *testing.T) {
+	a := Array{
+		{Key: int64(0), Value: "zero"},
+		{Key: "name", Value: "Bob"},
+	}
+
+	if v, ok := a.Get("0"); !ok || v != "zero" {
+		t.Errorf(`Get("0") = %v, %v, want zero`, v, ok)
+	}
+	if v, ok := a.Get("name"); !ok || v != "Bob" {
+		t.Errorf(`Get("name") = %v, %v, want Bob`, v, ok)
+	}
+	if _, ok := a.Get("missing"); ok {
+		t.Errorf(`Get("missing") found a value`)
+	}
+	if want := map[string]interface{}{"0": "zero", "name": "Bob"}; !reflect.DeepEqual(a.Map(), want) {
+		t.Errorf("Map() = %v, want %v", a.Map(), want)
+	}
+	if want := []interface{}{"zero", "Bob"}; !reflect.DeepEqual(a.List(), want) {
+		t.Errorf("List() = %v, want %v", a.List(), want)
+	}
+}
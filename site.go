@@ -0,0 +1,91 @@
+package wxr
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SiteURL returns base_site_url parsed as an absolute URL. On a multisite
+// network this is the address of the network's main site.
+func (c *Channel) SiteURL() (*url.URL, bool) {
+	return parseBaseURL(c.BaseSiteUrl)
+}
+
+// BlogURL returns the address of the exported blog: base_blog_url, or
+// base_site_url or the channel link if the export doesn't have one.
+func (c *Channel) BlogURL() (*url.URL, bool) {
+	for _, s := range []string{c.BaseBlogUrl, c.BaseSiteUrl, c.Link} {
+		if u, ok := parseBaseURL(s); ok {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+func parseBaseURL(s string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// IsInternal reports whether link, as found in the content of an item,
+// points into the exported blog. Relative links are resolved against the
+// blog's address. Hosts are compared without regard to case, scheme or a
+// leading "www.", since blogs are commonly reachable under all of them.
+//
+// On a multisite network that installs blogs in subdirectories, links to
+// the other blogs of the network are not internal.
+func (c *Channel) IsInternal(link string) bool {
+	_, ok := c.BlogPath(link)
+	return ok
+}
+
+// BlogPath returns the path of link relative to the root of the exported
+// blog, including any query and fragment, e.g. "/2021/03/hello/" for
+// "https://example.com/blog/2021/03/hello/" on a blog at
+// "https://example.com/blog". It reports false if link isn't internal.
+func (c *Channel) BlogPath(link string) (string, bool) {
+	base, ok := c.BlogURL()
+	if !ok {
+		return "", false
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	if canonicalHost(u.Host) != canonicalHost(base.Host) {
+		return "", false
+	}
+
+	root := strings.TrimSuffix(base.Path, "/")
+	p := u.Path
+	switch {
+	case p == root:
+		p = "/"
+	case strings.HasPrefix(p, root+"/"):
+		p = p[len(root):]
+	default:
+		return "", false
+	}
+
+	if u.RawQuery != "" {
+		p += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		p += "#" + u.Fragment
+	}
+	return p, true
+}
+
+// canonicalHost returns host in a form that compares equal for the
+// addresses a blog is commonly reachable under.
+func canonicalHost(host string) string {
+	host = strings.ToLower(host)
+	return strings.TrimPrefix(host, "www.")
+}
@@ -0,0 +1,70 @@
+package wxr
+
+import "testing"
+
+func TestChannelBlogURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Channel
+		want string
+	}{
+		{"blog url", Channel{BaseSiteUrl: "https://example.com", BaseBlogUrl: "https://example.com/blog"}, "https://example.com/blog"},
+		{"site url", Channel{BaseSiteUrl: " https://example.com ", Link: "https://example.org"}, "https://example.com"},
+		{"link", Channel{Link: "https://example.org"}, "https://example.org"},
+		{"relative", Channel{BaseBlogUrl: "/blog"}, ""},
+		{"none", Channel{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ok := tt.in.BlogURL()
+			if tt.want == "" {
+				if ok {
+					t.Errorf("BlogURL() = %v, want none", u)
+				}
+				return
+			}
+			if !ok || u.String() != tt.want {
+				t.Errorf("BlogURL() = %v, %v, want %s", u, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelBlogPath(t *testing.T) {
+	c := Channel{
+		BaseSiteUrl: "https://example.com",
+		BaseBlogUrl: "https://example.com/blog/",
+	}
+
+	tests := []struct {
+		name   string
+		link   string
+		want   string
+		wantOK bool
+	}{
+		{"post", "https://example.com/blog/2021/03/hello/", "/2021/03/hello/", true},
+		{"blog root", "https://example.com/blog", "/", true},
+		{"other scheme and www", "http://WWW.Example.com/blog/about/", "/about/", true},
+		{"query and fragment", "https://example.com/blog/?p=9#more", "/?p=9#more", true},
+		{"root relative", "/blog/about/", "/about/", true},
+		{"relative", "about/", "/about/", true},
+		{"other blog", "https://example.com/shop/cart/", "", false},
+		{"path prefix", "https://example.com/blogroll/", "", false},
+		{"other host", "https://example.org/blog/about/", "", false},
+		{"mailto", "mailto:me@example.com", "", false},
+		{"unparsable", "http://[::1", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.BlogPath(tt.link)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("BlogPath(%q) = %q, %v, want %q, %v", tt.link, got, ok, tt.want, tt.wantOK)
+			}
+			if c.IsInternal(tt.link) != tt.wantOK {
+				t.Errorf("IsInternal(%q) = %v, want %v", tt.link, !tt.wantOK, tt.wantOK)
+			}
+		})
+	}
+}
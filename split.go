@@ -0,0 +1,356 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// SplitOptions controls how Split partitions a document. Any combination
+// of limits may be set; a zero value disables the limit.
+type SplitOptions struct {
+	// MaxBytes limits the encoded size of each part. A part holding a
+	// single item may still exceed it if the item alone does.
+	MaxBytes int
+
+	// MaxItems limits the number of items in each part.
+	MaxItems int
+
+	// ByPostType and ByYear give the items of each post type, or each
+	// year of publication, parts of their own.
+	ByPostType bool
+	ByYear     bool
+}
+
+// Split partitions rss into several documents that each import on their
+// own, e.g. to stay under the upload limit or the execution time limit of
+// the WordPress Importer. Items keep their relative order within each
+// part, and parts are returned in the order their first item appears in
+// rss.
+//
+// The WordPress Importer only links an item to the items it refers to if
+// they are imported together, so items are kept in the same part as their
+// post_parent, their featured image and, for menu entries, the posts they
+// link to and their parent entries, as far as the limits allow. Where they
+// don't, or where ByPostType or ByYear put the items in different parts,
+// the part is given copies of the items it refers to, which may take it
+// over the limits. The importer skips a copy of a post it has already
+// imported, but still links to it.
+//
+// The first part carries all of the channel's authors and terms, so that
+// none of them are lost. The others carry the authors and terms their
+// items refer to, including the ancestors of those terms and the terms
+// menu entries link to.
+func Split(rss *RSS, opts SplitOptions) ([]*RSS, error) {
+	c := &rss.Channel
+
+	var groups [][]int
+	groupOf := make([]int, len(c.Items))
+	byKey := make(map[string]int)
+	for i := range c.Items {
+		it := &c.Items[i]
+		var key string
+		if opts.ByPostType {
+			key += it.PostType
+		}
+		if opts.ByYear {
+			key += "\x00" + strconv.Itoa(it.PublishedAt().Year())
+		}
+		g, ok := byKey[key]
+		if !ok {
+			g = len(groups)
+			byKey[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+		groupOf[i] = g
+	}
+
+	refs := itemRefs(c)
+	clusters := clusterItems(refs, groupOf)
+
+	// Every part is measured as if it carried all of the channel's
+	// authors and terms, which only overestimates the others.
+	var base int64
+	var sizes []int64
+	if opts.MaxBytes > 0 {
+		var err error
+		if base, err = encodedSize(rss); err != nil {
+			return nil, err
+		}
+		if sizes, err = itemSizes(rss); err != nil {
+			return nil, err
+		}
+	}
+	sizeOf := func(items []int) int64 {
+		var n int64
+		for _, i := range items {
+			if sizes != nil {
+				n += sizes[i]
+			}
+		}
+		return n
+	}
+	fits := func(items int, size int64) bool {
+		return (opts.MaxItems <= 0 || items <= opts.MaxItems) &&
+			(opts.MaxBytes <= 0 || size <= int64(opts.MaxBytes))
+	}
+
+	var chunks [][]int
+	placed := make([]bool, len(c.Items))
+	for _, g := range groups {
+		var chunk []int
+		size := base
+		add := func(items []int) {
+			n := sizeOf(items)
+			if len(chunk) > 0 && !fits(len(chunk)+len(items), size+n) {
+				chunks = append(chunks, chunk)
+				chunk, size = nil, base
+			}
+			chunk = append(chunk, items...)
+			size += n
+		}
+
+		for _, i := range g {
+			if placed[i] {
+				continue
+			}
+			cluster := clusters[i]
+			for _, j := range cluster {
+				placed[j] = true
+			}
+			if fits(len(cluster), base+sizeOf(cluster)) {
+				add(cluster)
+				continue
+			}
+			for _, j := range cluster {
+				add([]int{j})
+			}
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) == 0 {
+		chunks = [][]int{nil}
+	}
+
+	parts := make([]*RSS, len(chunks))
+	for i, chunk := range chunks {
+		part := *rss
+		part.Channel.Items = withReferenced(c.Items, chunk, refs)
+		if i > 0 {
+			keepReferenced(&part.Channel)
+		}
+		parts[i] = &part
+	}
+	return parts, nil
+}
+
+// itemRefs returns the indices of the items in c that each item refers to
+// by post ID: its post_parent and featured image and, for menu entries,
+// the post they link to and their parent entry.
+func itemRefs(c *Channel) [][]int {
+	byID := make(map[int]int, len(c.Items))
+	for i := range c.Items {
+		if id := c.Items[i].PostID; id != 0 {
+			if _, ok := byID[id]; !ok {
+				byID[id] = i
+			}
+		}
+	}
+
+	refs := make([][]int, len(c.Items))
+	for i := range c.Items {
+		it := &c.Items[i]
+		ids := []int{it.PostParent}
+		if id, err := strconv.Atoi(metaValue(it, metaThumbnailID)); err == nil {
+			ids = append(ids, id)
+		}
+		if it.PostType == PostTypeMenuItem {
+			if id, err := strconv.Atoi(metaValue(it, metaMenuItemParent)); err == nil {
+				ids = append(ids, id)
+			}
+			if metaValue(it, metaMenuItemType) == MenuItemPostType {
+				if id, err := strconv.Atoi(metaValue(it, metaMenuItemObjectID)); err == nil {
+					ids = append(ids, id)
+				}
+			}
+		}
+
+		for _, id := range ids {
+			if j, ok := byID[id]; ok && id != 0 && j != i {
+				refs[i] = append(refs[i], j)
+			}
+		}
+	}
+	return refs
+}
+
+// clusterItems returns, for each item, the indices in order of the items
+// that refer to each other, directly or through others, and that are in
+// the same group.
+func clusterItems(refs [][]int, groupOf []int) [][]int {
+	root := make([]int, len(refs))
+	for i := range root {
+		root[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if root[i] != i {
+			root[i] = find(root[i])
+		}
+		return root[i]
+	}
+	for i, targets := range refs {
+		for _, j := range targets {
+			if groupOf[i] == groupOf[j] {
+				root[find(i)] = find(j)
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range refs {
+		r := find(i)
+		members[r] = append(members[r], i)
+	}
+	clusters := make([][]int, len(refs))
+	for i := range refs {
+		clusters[i] = members[find(i)]
+	}
+	return clusters
+}
+
+// withReferenced returns the items at the given indices, together with
+// copies of the items they refer to, directly or through others, in the
+// order they appear in items.
+func withReferenced(items []Item, indices []int, refs [][]int) []Item {
+	in := make([]bool, len(items))
+	queue := append([]int(nil), indices...)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		if in[i] {
+			continue
+		}
+		in[i] = true
+		queue = append(queue, refs[i]...)
+	}
+
+	var part []Item
+	for i, ok := range in {
+		if ok {
+			part = append(part, items[i])
+		}
+	}
+	return part
+}
+
+// encodedSize returns the size of rss encoded without its items.
+func encodedSize(rss *RSS) (int64, error) {
+	shell := *rss
+	shell.Channel.Items = nil
+
+	var w countingWriter
+	if err := Encode(&w, &shell); err != nil {
+		return 0, fmt.Errorf("wxr: measuring export: %w", err)
+	}
+	return int64(w), nil
+}
+
+// itemSizes returns the number of bytes each item of rss adds to its
+// encoded size. The items are encoded in turn on their own, without the
+// authors and terms that precede them.
+func itemSizes(rss *RSS) ([]int64, error) {
+	var w countingWriter
+	enc := NewEncoder(&w)
+	if _, _, err := enc.open(rss); err != nil {
+		return nil, fmt.Errorf("wxr: measuring export: %w", err)
+	}
+	// Items are indented as they are within <channel>.
+	enc.token(xml.StartElement{Name: xml.Name{Local: "channel"}})
+
+	items := rss.Channel.Items
+	sizes := make([]int64, len(items))
+	for i := range items {
+		enc.flush()
+		start := w
+		enc.item(&items[i])
+		enc.flush()
+		if enc.err != nil {
+			return nil, fmt.Errorf("wxr: measuring export: %w", enc.err)
+		}
+		sizes[i] = int64(w - start)
+	}
+	return sizes, nil
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// keepReferenced drops the authors and terms of c that its items don't
+// refer to.
+func keepReferenced(c *Channel) {
+	logins := make(map[string]bool)
+	keys := make(map[termKey]bool)
+	termIDs := make(map[int]bool)
+	for i := range c.Items {
+		it := &c.Items[i]
+		logins[it.Creator] = true
+		for _, cat := range it.Category {
+			keys[termKey{cat.Domain, cat.NiceName}] = true
+		}
+		if it.PostType == PostTypeMenuItem && metaValue(it, metaMenuItemType) == MenuItemTaxonomy {
+			if id, err := strconv.Atoi(metaValue(it, metaMenuItemObjectID)); err == nil {
+				termIDs[id] = true
+			}
+		}
+	}
+
+	// The importer only files a term under a parent it knows of, so bring
+	// the ancestors along for the hierarchy to survive.
+	terms := append(c.builtinTerms(), c.Terms...)
+	parents := make(map[termKey]string, len(terms))
+	for _, t := range terms {
+		k := termKey{t.Taxonomy, t.Slug}
+		parents[k] = t.Parent
+		if termIDs[t.ID] {
+			keys[k] = true
+		}
+	}
+	for k := range keys {
+		for p := parents[k]; p != "" && !keys[termKey{k.taxonomy, p}]; p = parents[termKey{k.taxonomy, p}] {
+			keys[termKey{k.taxonomy, p}] = true
+		}
+	}
+
+	var authors []Author
+	for _, a := range c.Authors {
+		if logins[a.Login] {
+			authors = append(authors, a)
+		}
+	}
+	var cats []Category
+	for _, cat := range c.Categories {
+		if keys[termKey{TaxonomyCategory, cat.NiceName}] {
+			cats = append(cats, cat)
+		}
+	}
+	var tags []Tag
+	for _, t := range c.Tags {
+		if keys[termKey{TaxonomyTag, t.Slug}] {
+			tags = append(tags, t)
+		}
+	}
+	var others []Term
+	for _, t := range c.Terms {
+		if keys[termKey{t.Taxonomy, t.Slug}] {
+			others = append(others, t)
+		}
+	}
+	c.Authors, c.Categories, c.Tags, c.Terms = authors, cats, tags, others
+}
@@ -0,0 +1,245 @@
+package wxr
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const splitFragment = `
+	<wp:author><wp:author_login>alice</wp:author_login></wp:author>
+	<wp:author><wp:author_login>bob</wp:author_login></wp:author>
+	<wp:author><wp:author_login>carol</wp:author_login></wp:author>
+	<wp:category><wp:term_id>1</wp:term_id><wp:category_nicename>news</wp:category_nicename><wp:category_parent></wp:category_parent><wp:cat_name>News</wp:cat_name></wp:category>
+	<wp:category><wp:term_id>2</wp:term_id><wp:category_nicename>local</wp:category_nicename><wp:category_parent>news</wp:category_parent><wp:cat_name>Local</wp:cat_name></wp:category>
+	<wp:category><wp:term_id>3</wp:term_id><wp:category_nicename>sport</wp:category_nicename><wp:cat_name>Sport</wp:cat_name></wp:category>
+	<wp:tag><wp:term_id>4</wp:term_id><wp:tag_slug>go</wp:tag_slug><wp:tag_name>Go</wp:tag_name></wp:tag>
+	<wp:term><wp:term_id>5</wp:term_id><wp:term_taxonomy>nav_menu</wp:term_taxonomy><wp:term_slug>main</wp:term_slug><wp:term_name>Main</wp:term_name></wp:term>
+	<item>
+		<title>One</title>
+		<dc:creator>alice</dc:creator>
+		<wp:post_id>1</wp:post_id>
+		<wp:post_date>2020-01-01 00:00:00</wp:post_date>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="local"><![CDATA[Local]]></category>
+	</item>
+	<item>
+		<title>Two</title>
+		<dc:creator>bob</dc:creator>
+		<wp:post_id>2</wp:post_id>
+		<wp:post_date>2021-01-01 00:00:00</wp:post_date>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>Three</title>
+		<dc:creator>alice</dc:creator>
+		<wp:post_id>3</wp:post_id>
+		<wp:post_date>2021-06-01 00:00:00</wp:post_date>
+		<wp:post_type>post</wp:post_type>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+	</item>` +
+	`<item>
+		<title>Sport</title>
+		<wp:post_id>4</wp:post_id>
+		<wp:post_date>2021-06-01 00:00:00</wp:post_date>
+		<wp:post_type>nav_menu_item</wp:post_type>
+		<category domain="nav_menu" nicename="main"><![CDATA[Main]]></category>
+		<wp:postmeta><wp:meta_key>_menu_item_type</wp:meta_key><wp:meta_value>taxonomy</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_object_id</wp:meta_key><wp:meta_value>3</wp:meta_value></wp:postmeta>
+	</item>`
+
+// describeParts summarizes each part as its item titles followed by the
+// authors and terms it defines.
+func describeParts(parts []*RSS) []string {
+	var got []string
+	for _, p := range parts {
+		var s []string
+		for _, it := range p.Channel.Items {
+			s = append(s, it.Title)
+		}
+		s = append(s, "|")
+		for _, a := range p.Channel.Authors {
+			s = append(s, a.Login)
+		}
+		for _, t := range p.Channel.builtinTerms() {
+			s = append(s, t.Slug)
+		}
+		for _, t := range p.Channel.Terms {
+			s = append(s, t.Slug)
+		}
+		got = append(got, strings.Join(s, " "))
+	}
+	return got
+}
+
+func TestSplit(t *testing.T) {
+	rss, err := Decode(strings.NewReader(wrapChannel(splitFragment)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	all := "alice bob carol news local sport go main"
+	tests := []struct {
+		name string
+		opts SplitOptions
+		want []string
+	}{
+		{"none", SplitOptions{}, []string{"One Two Three Sport | " + all}},
+		{"limit items", SplitOptions{MaxItems: 2}, []string{
+			"One Two | " + all,
+			"Three Sport | alice sport go main",
+		}},
+		{"post type", SplitOptions{ByPostType: true}, []string{
+			"One Three | " + all,
+			"Two | bob",
+			"Sport | sport main",
+		}},
+		{"year", SplitOptions{ByYear: true}, []string{
+			"One | " + all,
+			"Two Three Sport | alice bob sport go main",
+		}},
+		{"post type and year", SplitOptions{ByPostType: true, ByYear: true, MaxItems: 1}, []string{
+			"One | " + all,
+			"Two | bob",
+			"Three | alice go",
+			"Sport | sport main",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := Split(rss, tt.opts)
+			if err != nil {
+				t.Fatalf("Split failed: %v", err)
+			}
+			if got := describeParts(parts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split parts =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	if len(rss.Channel.Items) != 4 || len(rss.Channel.Authors) != 3 {
+		t.Errorf("Split modified its input")
+	}
+}
+
+func TestSplitMaxBytes(t *testing.T) {
+	rss, err := Decode(strings.NewReader(wrapChannel(splitFragment)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	var whole bytes.Buffer
+	if err := Encode(&whole, rss); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	limit := whole.Len() - 1
+
+	parts, err := Split(rss, SplitOptions{MaxBytes: limit})
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want the export split in at least 2", len(parts))
+	}
+
+	var items int
+	for i, p := range parts {
+		var b bytes.Buffer
+		if err := Encode(&b, p); err != nil {
+			t.Fatalf("Encode part %d failed: %v", i, err)
+		}
+		if b.Len() > limit {
+			t.Errorf("part %d is %d bytes, want at most %d", i, b.Len(), limit)
+		}
+		if _, err := Decode(&b); err != nil {
+			t.Errorf("part %d doesn't decode: %v", i, err)
+		}
+		items += len(p.Channel.Items)
+	}
+	if items != 4 {
+		t.Errorf("parts hold %d items, want 4", items)
+	}
+
+	// An item bigger than the limit still gets a part of its own.
+	parts, err = Split(rss, SplitOptions{MaxBytes: 1})
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(parts) != 4 {
+		t.Errorf("got %d parts, want one per item", len(parts))
+	}
+}
+
+const splitReferencesFragment = `
+	<item>
+		<title>About</title>
+		<wp:post_id>10</wp:post_id>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>Other</title>
+		<wp:post_id>11</wp:post_id>
+		<wp:post_type>post</wp:post_type>
+	</item>
+	<item>
+		<title>Team</title>
+		<wp:post_id>12</wp:post_id>
+		<wp:post_parent>10</wp:post_parent>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>Photo</title>
+		<wp:post_id>13</wp:post_id>
+		<wp:post_parent>12</wp:post_parent>
+		<wp:post_type>attachment</wp:post_type>
+	</item>
+	<item>
+		<title>Menu</title>
+		<wp:post_id>14</wp:post_id>
+		<wp:post_type>nav_menu_item</wp:post_type>
+		<wp:postmeta><wp:meta_key>_menu_item_type</wp:meta_key><wp:meta_value>post_type</wp:meta_value></wp:postmeta>
+		<wp:postmeta><wp:meta_key>_menu_item_object_id</wp:meta_key><wp:meta_value>10</wp:meta_value></wp:postmeta>
+	</item>`
+
+func TestSplitReferences(t *testing.T) {
+	rss, err := Decode(strings.NewReader(wrapChannel(splitReferencesFragment)))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts SplitOptions
+		want []string
+	}{
+		{"kept together", SplitOptions{MaxItems: 4}, []string{
+			"About Team Photo Menu |",
+			"Other |",
+		}},
+		{"copied", SplitOptions{MaxItems: 2}, []string{
+			"About Team |",
+			"About Team Photo Menu |",
+			"Other |",
+		}},
+		{"post type", SplitOptions{ByPostType: true}, []string{
+			"About Team |",
+			"Other |",
+			"About Team Photo |",
+			"About Menu |",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := Split(rss, tt.opts)
+			if err != nil {
+				t.Fatalf("Split failed: %v", err)
+			}
+			if got := describeParts(parts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split parts =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package wxr
+
+// Term returns the category as a term of the "category" taxonomy, which
+// is what it is in WordPress; exports merely give categories an element
+// of their own.
+func (c Category) Term() Term {
+	return Term{
+		ID:          c.TermID,
+		Taxonomy:    TaxonomyCategory,
+		Slug:        c.NiceName,
+		Parent:      c.Parent,
+		Name:        c.Name,
+		Description: c.Description,
+		MetaKVs:     c.MetaKVs,
+	}
+}
+
+// Term returns the tag as a term of the "post_tag" taxonomy.
+func (t Tag) Term() Term {
+	return Term{
+		ID:          t.TermID,
+		Taxonomy:    TaxonomyTag,
+		Slug:        t.Slug,
+		Name:        t.Name,
+		Description: t.Description,
+		MetaKVs:     t.MetaKVs,
+	}
+}
+
+// builtinTerms returns the channel's categories and tags as terms. They are
+// exported in elements of their own, but are terms like any other.
+func (c *Channel) builtinTerms() []Term {
+	terms := make([]Term, 0, len(c.Categories)+len(c.Tags))
+	for _, cat := range c.Categories {
+		terms = append(terms, cat.Term())
+	}
+	for _, t := range c.Tags {
+		terms = append(terms, t.Term())
+	}
+	return terms
+}
+
+// Meta returns the value of the term's first termmeta with the given
+// key.
+func (t Term) Meta(key string) (string, bool) {
+	for _, m := range t.MetaKVs {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// A Taxonomy is the hierarchy of the terms of one taxonomy, such as
+// "category" or a custom taxonomy like "product_cat".
+type Taxonomy struct {
+	Name string
+
+	// Terms holds every term in the taxonomy, in the order they appear in
+	// the export.
+	Terms []*TaxonomyTerm
+
+	// Roots holds the terms that have no parent, in export order.
+	Roots []*TaxonomyTerm
+
+	bySlug map[string]*TaxonomyTerm
+}
+
+// A TaxonomyTerm is a term placed in its taxonomy's hierarchy.
+type TaxonomyTerm struct {
+	Term
+
+	// ParentTerm is the term named by Parent, or nil for a root term.
+	ParentTerm *TaxonomyTerm
+
+	Children []*TaxonomyTerm
+}
+
+// Path returns the term's ancestors, starting at the root, followed by the
+// term itself.
+func (t *TaxonomyTerm) Path() []*TaxonomyTerm {
+	var path []*TaxonomyTerm
+	for p := t; p != nil; p = p.ParentTerm {
+		path = append(path, p)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Term returns the term whose slug is slug.
+func (tx *Taxonomy) Term(slug string) (*TaxonomyTerm, bool) {
+	t, ok := tx.bySlug[slug]
+	return t, ok
+}
+
+// Taxonomies builds the hierarchy of every taxonomy that has terms in the
+// channel, keyed by taxonomy name. Categories and tags are included under
+// TaxonomyCategory and TaxonomyTag.
+//
+// A term whose parent isn't in the export, or whose parent would make the
+// hierarchy circular, becomes a root.
+func (c *Channel) Taxonomies() map[string]*Taxonomy {
+	taxonomies := make(map[string]*Taxonomy)
+
+	add := func(t Term) {
+		tx, ok := taxonomies[t.Taxonomy]
+		if !ok {
+			tx = &Taxonomy{Name: t.Taxonomy, bySlug: make(map[string]*TaxonomyTerm)}
+			taxonomies[t.Taxonomy] = tx
+		}
+		if _, ok := tx.bySlug[t.Slug]; ok {
+			return
+		}
+		tt := &TaxonomyTerm{Term: t}
+		tx.Terms = append(tx.Terms, tt)
+		tx.bySlug[t.Slug] = tt
+	}
+
+	for _, t := range c.Terms {
+		add(t)
+	}
+	for _, t := range c.builtinTerms() {
+		add(t)
+	}
+
+	for _, tx := range taxonomies {
+		for _, t := range tx.Terms {
+			p, ok := tx.bySlug[t.Parent]
+			if !ok || t.Parent == "" || p.descendsFrom(t) {
+				tx.Roots = append(tx.Roots, t)
+				continue
+			}
+			t.ParentTerm = p
+			p.Children = append(p.Children, t)
+		}
+	}
+
+	return taxonomies
+}
+
+// descendsFrom reports whether ancestor is t or one of t's ancestors.
+func (t *TaxonomyTerm) descendsFrom(ancestor *TaxonomyTerm) bool {
+	for p := t; p != nil; p = p.ParentTerm {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
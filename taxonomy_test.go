@@ -0,0 +1,72 @@
+package wxr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChannelTaxonomies(t *testing.T) {
+	c := &Channel{
+		Categories: []Category{
+			{TermID: 1, NiceName: "news", Name: "News"},
+			{TermID: 2, NiceName: "local", Parent: "news", Name: "Local", Description: "Nearby"},
+			{TermID: 3, NiceName: "sports", Parent: "local", Name: "Sports"},
+			{TermID: 4, NiceName: "orphan", Parent: "gone", Name: "Orphan"},
+		},
+		Tags: []Tag{
+			{TermID: 8, Slug: "go", Name: "Go", Description: "Gophers"},
+		},
+		Terms: []Term{
+			{ID: 5, Taxonomy: "series", Slug: "a", Parent: "b", Name: "A"},
+			{ID: 6, Taxonomy: "series", Slug: "b", Parent: "a", Name: "B"},
+			{ID: 7, Taxonomy: "product_cat", Slug: "shoes", Name: "Shoes",
+				MetaKVs: []TermMeta{{Key: "thumbnail_id", Value: "42"}}},
+		},
+	}
+
+	taxonomies := c.Taxonomies()
+	if len(taxonomies) != 4 {
+		t.Fatalf("got %d taxonomies, want 4", len(taxonomies))
+	}
+	if tag, ok := taxonomies[TaxonomyTag].Term("go"); !ok || tag.Description != "Gophers" || tag.ID != 8 {
+		t.Errorf(`tag "go" = %+v, %v`, tag, ok)
+	}
+
+	cats := taxonomies[TaxonomyCategory]
+	if got := slugs(cats.Roots); !reflect.DeepEqual(got, []string{"news", "orphan"}) {
+		t.Errorf("category roots = %v", got)
+	}
+	sports, ok := cats.Term("sports")
+	if !ok {
+		t.Fatalf(`Term("sports") not found`)
+	}
+	if got := slugs(sports.Path()); !reflect.DeepEqual(got, []string{"news", "local", "sports"}) {
+		t.Errorf("sports path = %v", got)
+	}
+	if local := sports.ParentTerm; local.Description != "Nearby" || len(local.Children) != 1 {
+		t.Errorf("local = %+v", local)
+	}
+
+	// A circular hierarchy is broken rather than followed forever.
+	series := taxonomies["series"]
+	if got := slugs(series.Roots); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("series roots = %v", got)
+	}
+	a, _ := series.Term("a")
+	if got := slugs(a.Path()); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("series path = %v", got)
+	}
+
+	shoes, _ := taxonomies["product_cat"].Term("shoes")
+	if v, ok := shoes.Meta("thumbnail_id"); !ok || v != "42" {
+		t.Errorf(`Meta("thumbnail_id") = %q, %v`, v, ok)
+	}
+}
+
+func slugs(terms []*TaxonomyTerm) []string {
+	var s []string
+	for _, t := range terms {
+		s = append(s, t.Slug)
+	}
+	return s
+}
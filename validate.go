@@ -0,0 +1,197 @@
+package wxr
+
+import (
+	"fmt"
+	"time"
+)
+
+// Severity ranks how much a Diagnostic matters.
+type Severity int
+
+const (
+	// SeverityWarning marks a problem the WordPress Importer works
+	// around, e.g. by assigning posts of an unknown author to the
+	// importing user.
+	SeverityWarning Severity = iota
+
+	// SeverityError marks a problem that loses or corrupts data on
+	// import.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// A DiagnosticCode identifies the kind of problem a Diagnostic describes.
+type DiagnosticCode string
+
+// Problems found by Validate.
+const (
+	// DuplicatePostID is reported for an item whose post ID was already
+	// used by an earlier item.
+	DuplicatePostID DiagnosticCode = "duplicate-post-id"
+
+	// MissingParent is reported for an item whose post_parent isn't in
+	// the export.
+	MissingParent DiagnosticCode = "missing-parent"
+
+	// UnknownAuthor is reported for an item whose dc:creator isn't one of
+	// the channel's authors.
+	UnknownAuthor DiagnosticCode = "unknown-author"
+
+	// UndefinedTerm is reported for an item category, category parent or
+	// term parent that refers to a term the channel doesn't define.
+	UndefinedTerm DiagnosticCode = "undefined-term"
+
+	// InvalidDate is reported for a date that doesn't parse as DateLayout.
+	InvalidDate DiagnosticCode = "invalid-date"
+
+	// UnknownStatus is reported for an item whose status isn't built into
+	// WordPress. Plugins may register their own statuses, so this is only
+	// a warning.
+	UnknownStatus DiagnosticCode = "unknown-status"
+)
+
+// A Diagnostic describes a structural problem in a WXR document.
+type Diagnostic struct {
+	Severity Severity
+	Code     DiagnosticCode
+
+	// PostID is the ID of the item the problem was found in, or 0 if it
+	// was found elsewhere in the channel.
+	PostID int
+
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.PostID == 0 {
+		return fmt.Sprintf("%s: %s: %s", d.Severity, d.Code, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: post %d: %s", d.Severity, d.Code, d.PostID, d.Message)
+}
+
+// Validate checks the references between the items, authors and terms of
+// rss, and the values WordPress expects to be well formed. It returns the
+// problems found in document order, or nil if there are none.
+//
+// Item categories are only checked against taxonomies the channel defines
+// at least one term for, plus categories, since exports don't always
+// include the definitions of every taxonomy.
+func Validate(rss *RSS) []Diagnostic {
+	v := validator{idx: rss.Channel.Index()}
+	v.channel(&rss.Channel)
+	return v.diags
+}
+
+type validator struct {
+	idx   *Index
+	diags []Diagnostic
+}
+
+func (v *validator) report(sev Severity, code DiagnosticCode, postID int, format string, args ...interface{}) {
+	v.diags = append(v.diags, Diagnostic{
+		Severity: sev,
+		Code:     code,
+		PostID:   postID,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) channel(c *Channel) {
+	taxonomies := map[string]bool{TaxonomyCategory: true}
+	if len(c.Tags) > 0 {
+		taxonomies[TaxonomyTag] = true
+	}
+	for _, t := range c.Terms {
+		taxonomies[t.Taxonomy] = true
+	}
+
+	for _, cat := range c.Categories {
+		if cat.Parent != "" {
+			if _, ok := v.idx.Term(TaxonomyCategory, cat.Parent); !ok {
+				v.report(SeverityWarning, UndefinedTerm, 0, "category %q has undefined parent %q", cat.NiceName, cat.Parent)
+			}
+		}
+	}
+	for _, t := range c.Terms {
+		if t.Parent != "" {
+			if _, ok := v.idx.Term(t.Taxonomy, t.Parent); !ok {
+				v.report(SeverityWarning, UndefinedTerm, 0, "%s term %q has undefined parent %q", t.Taxonomy, t.Slug, t.Parent)
+			}
+		}
+	}
+
+	seen := make(map[int]bool, len(c.Items))
+	for i := range c.Items {
+		it := &c.Items[i]
+		if it.PostID != 0 {
+			if seen[it.PostID] {
+				v.report(SeverityError, DuplicatePostID, it.PostID, "%q reuses the ID of an earlier item", it.Title)
+			}
+			seen[it.PostID] = true
+		}
+		v.item(it, taxonomies)
+	}
+}
+
+func (v *validator) item(it *Item, taxonomies map[string]bool) {
+	id := it.PostID
+
+	if it.PostParent != 0 {
+		if _, ok := v.idx.Item(it.PostParent); !ok {
+			v.report(SeverityError, MissingParent, id, "parent %d is not in the export", it.PostParent)
+		}
+	}
+
+	if it.Creator != "" {
+		if _, ok := v.idx.Author(it.Creator); !ok {
+			v.report(SeverityWarning, UnknownAuthor, id, "creator %q is not a channel author", it.Creator)
+		}
+	}
+
+	for _, c := range it.Category {
+		if !taxonomies[c.Domain] || c.NiceName == "" {
+			continue
+		}
+		if _, ok := v.idx.Term(c.Domain, c.NiceName); !ok {
+			v.report(SeverityWarning, UndefinedTerm, id, "%s %q is not defined", c.Domain, c.NiceName)
+		}
+	}
+
+	if s := it.Status(); s != "" && !s.Known() {
+		v.report(SeverityWarning, UnknownStatus, id, "unknown status %q", s)
+	}
+
+	v.date(id, "post_date", it.PostDate)
+	v.date(id, "post_date_gmt", it.PostDateGMT)
+	v.date(id, "post_modified", it.PostModified)
+	v.date(id, "post_modified_gmt", it.PostModifiedGMT)
+	for _, c := range it.Comments {
+		v.date(id, fmt.Sprintf("comment %d comment_date", c.ID), c.Date)
+		v.date(id, fmt.Sprintf("comment %d comment_date_gmt", c.ID), c.DateGMT)
+	}
+}
+
+func (v *validator) date(postID int, field, s string) {
+	if _, err := ParseDate(s, time.UTC); err != nil {
+		v.report(SeverityError, InvalidDate, postID, "%s %q is not a valid date", field, s)
+	}
+}
+
+// HasErrors reports whether any of diags is an error.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity >= SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package wxr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	rss := &RSS{Channel: Channel{
+		Authors:    []Author{{Login: "alice"}},
+		Categories: []Category{{NiceName: "news"}, {NiceName: "local", Parent: "regional"}},
+		Terms:      []Term{{Taxonomy: "genre", Slug: "fiction"}},
+		Items: []Item{
+			{PostID: 1, Creator: "alice", RawStatus: "publish", PostDate: "2021-08-07 07:56:40", PostDateGMT: "0000-00-00 00:00:00",
+				Category: []ItemCategory{
+					{Domain: "category", NiceName: "news"},
+					{Domain: "post_tag", NiceName: "untracked"},
+					{Domain: "genre", NiceName: "fiction"},
+				}},
+			{PostID: 2, Creator: "bob", RawStatus: "archived", PostParent: 1,
+				Category: []ItemCategory{{Domain: "genre", NiceName: "poetry"}}},
+			{PostID: 1, Title: "again", PostParent: 42, PostModified: "yesterday",
+				Comments: []Comment{{ID: 3, Date: "2021-13-01 00:00:00"}}},
+		},
+	}}
+
+	want := []Diagnostic{
+		{SeverityWarning, UndefinedTerm, 0, `category "local" has undefined parent "regional"`},
+		{SeverityWarning, UnknownAuthor, 2, `creator "bob" is not a channel author`},
+		{SeverityWarning, UndefinedTerm, 2, `genre "poetry" is not defined`},
+		{SeverityWarning, UnknownStatus, 2, `unknown status "archived"`},
+		{SeverityError, DuplicatePostID, 1, `"again" reuses the ID of an earlier item`},
+		{SeverityError, MissingParent, 1, `parent 42 is not in the export`},
+		{SeverityError, InvalidDate, 1, `post_modified "yesterday" is not a valid date`},
+		{SeverityError, InvalidDate, 1, `comment 3 comment_date "2021-13-01 00:00:00" is not a valid date`},
+	}
+
+	got := Validate(rss)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate got:\n%v\nwant:\n%v", got, want)
+	}
+	if !HasErrors(got) {
+		t.Errorf("HasErrors = false, want true")
+	}
+}
+
+func TestValidateClean(t *testing.T) {
+	in := wrapChannel(`
+		<wp:author><wp:author_login>alice</wp:author_login></wp:author>
+		<wp:category><wp:category_nicename>news</wp:category_nicename></wp:category>
+		<wp:category><wp:category_nicename>local</wp:category_nicename><wp:category_parent>news</wp:category_parent></wp:category>
+		<item>
+			<dc:creator>alice</dc:creator>
+			<wp:post_id>1</wp:post_id>
+			<wp:post_date>2021-08-07 07:56:40</wp:post_date>
+			<wp:post_date_gmt>0000-00-00 00:00:00</wp:post_date_gmt>
+			<wp:status>draft</wp:status>
+			<category domain="category" nicename="local">Local</category>
+			<category domain="post_tag" nicename="go">Go</category>
+		</item>
+		<item>
+			<wp:post_id>2</wp:post_id>
+			<wp:post_parent>1</wp:post_parent>
+			<wp:status>inherit</wp:status>
+		</item>`)
+
+	rss, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if diags := Validate(rss); len(diags) != 0 {
+		t.Errorf("Validate got %v, want no diagnostics", diags)
+	}
+}
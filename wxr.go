@@ -13,11 +13,13 @@ type Author struct {
 }
 
 type Category struct {
-	XMLName  xml.Name `xml:"category"`
-	TermID   int      `xml:"term_id"`
-	NiceName string   `xml:"category_nicename"`
-	Parent   string   `xml:"category_parent"`
-	Name     string   `xml:"cat_name"`
+	XMLName     xml.Name   `xml:"category"`
+	TermID      int        `xml:"term_id"`
+	NiceName    string     `xml:"category_nicename"`
+	Parent      string     `xml:"category_parent"`
+	Name        string     `xml:"cat_name"`
+	Description string     `xml:"category_description"`
+	MetaKVs     []TermMeta `xml:"termmeta"`
 }
 
 type Channel struct {
@@ -32,10 +34,38 @@ type Channel struct {
 	BaseBlogUrl string     `xml:"base_blog_url"`
 	Authors     []Author   `xml:"author"`
 	Categories  []Category `xml:"category"`
+	Tags        []Tag      `xml:"tag"`
 	Terms       []Term     `xml:"term"`
 	Generator   string     `xml:"generator"`
 	Site        Site       `xml:"site"`
 	Items       []Item     `xml:"item"`
+
+	// Extra holds the children of <channel> that aren't modeled above,
+	// such as those added by plugins, in document order.
+	Extra []Element `xml:",any"`
+}
+
+type Comment struct {
+	XMLName     xml.Name      `xml:"comment"`
+	ID          int           `xml:"comment_id"`
+	Author      string        `xml:"comment_author"`
+	AuthorEmail string        `xml:"comment_author_email"`
+	AuthorURL   string        `xml:"comment_author_url"`
+	AuthorIP    string        `xml:"comment_author_IP"`
+	Date        string        `xml:"comment_date"`
+	DateGMT     string        `xml:"comment_date_gmt"`
+	Content     string        `xml:"comment_content"`
+	Approved    string        `xml:"comment_approved"`
+	Type        string        `xml:"comment_type"`
+	Parent      int           `xml:"comment_parent"`
+	UserID      int           `xml:"comment_user_id"`
+	MetaKVs     []CommentMeta `xml:"commentmeta"`
+}
+
+type CommentMeta struct {
+	XMLName xml.Name `xml:"commentmeta"`
+	Key     string   `xml:"meta_key"`
+	Value   string   `xml:"meta_value"`
 }
 
 type Content struct {
@@ -43,6 +73,16 @@ type Content struct {
 	Data    string   `xml:",cdata"`
 }
 
+// An Element is an XML element the package doesn't model. Its attributes
+// and content are kept verbatim so that it can be inspected and encoded
+// again unchanged. Namespace declarations made on the <channel> or <item>
+// it was found in are copied to Attrs.
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
 type Excerpt struct {
 	XMLName xml.Name `xml:"encoded"`
 	Data    string   `xml:",cdata"`
@@ -51,12 +91,14 @@ type Excerpt struct {
 type GUID struct {
 	XMLName     xml.Name `xml:"guid"`
 	IsPermaLink string   `xml:"isPermaLink,attr"`
+	Value       string   `xml:",chardata"`
 }
 
 type ItemCategory struct {
 	XMLName  xml.Name `xml:"category"`
 	Domain   string   `xml:"domain,attr"`
 	NiceName string   `xml:"nicename,attr"`
+	Name     string   `xml:",chardata"`
 }
 
 type Item struct {
@@ -68,33 +110,48 @@ type Item struct {
 	GUID        GUID     `xml:"guid"`
 	Description string   `xml:"description"`
 
-	// FIXME: Using these URLs here is brittle. If WordPress ever changes
-	// the URLs associated with content or excerpt this will break. However,
-	// I can't get the namespace shortnames to work here:
-	//
-	// `xml:"content encoded"`
-	// `xml:"excerpt encoded"`
-	//
-	// Despite these being set in the top-level <rss> tag.
-	Content Content `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
-	Excerpt Excerpt `xml:"http://wordpress.org/export/1.2/excerpt/ encoded"`
-
-	PostID          int          `xml:"post_id"`
-	PostDate        string       `xml:"post_date"`
-	PostDateGMT     string       `xml:"post_date_gmt"`
-	PostModified    string       `xml:"post_modified"`
-	PostModifiedGMT string       `xml:"post_modified_gmt"`
-	CommentStatus   string       `xml:"comment_status"`
-	PingStatus      string       `xml:"ping_status"`
-	Status          string       `xml:"status"`
-	PostName        string       `xml:"post_name"`
-	PostParent      int          `xml:"post_parent"`
-	MenuOrder       int          `xml:"menu_order"`
-	PostType        string       `xml:"post_type"`
-	PostPassword    string       `xml:"post_password"`
-	IsSticky        int          `xml:"is_sticky"`
-	Category        ItemCategory `xml:"category"`
-	MetaKVs         []PostMeta   `xml:"postmeta"`
+	// The content: and excerpt: prefixes are bound to different namespace
+	// URLs depending on the WXR version, so Item implements
+	// xml.Unmarshaler to resolve them rather than relying on these tags.
+	Content Content `xml:"content encoded"`
+	Excerpt Excerpt `xml:"excerpt encoded"`
+
+	PostID          int    `xml:"post_id"`
+	PostDate        string `xml:"post_date"`
+	PostDateGMT     string `xml:"post_date_gmt"`
+	PostModified    string `xml:"post_modified"`
+	PostModifiedGMT string `xml:"post_modified_gmt"`
+	CommentStatus   string `xml:"comment_status"`
+	PingStatus      string `xml:"ping_status"`
+
+	// RawStatus is the wp:status exactly as exported, see Item.Status.
+	RawStatus string `xml:"status"`
+
+	PostName     string `xml:"post_name"`
+	PostParent   int    `xml:"post_parent"`
+	MenuOrder    int    `xml:"menu_order"`
+	PostType     string `xml:"post_type"`
+	PostPassword string `xml:"post_password"`
+
+	// Sticky is the wp:is_sticky flag exactly as exported, see
+	// Item.IsSticky.
+	Sticky int `xml:"is_sticky"`
+
+	// AttachmentURL is the address of the uploaded file for items whose
+	// PostType is "attachment", see Item.Attachment.
+	AttachmentURL string `xml:"attachment_url"`
+
+	MetaKVs  []PostMeta `xml:"postmeta"`
+	Comments []Comment  `xml:"comment"`
+
+	// Category holds every <category> element attached to the item, which
+	// covers categories, tags, nav menus and any custom taxonomy. Their
+	// Domain names the taxonomy.
+	Category []ItemCategory `xml:"category"`
+
+	// Extra holds the children of <item> that aren't modeled above, such
+	// as those added by plugins, in document order.
+	Extra []Element `xml:",any"`
 }
 
 type PostMeta struct {
@@ -112,18 +169,43 @@ type RSS struct {
 	Dc      string   `xml:"dc,attr"`
 	Wp      string   `xml:"wp,attr"`
 	Channel Channel  `xml:"channel"`
+
+	// Attrs holds the other attributes of <rss>, notably the namespace
+	// declarations of plugin vocabularies used by Extra elements.
+	Attrs []xml.Attr `xml:",any,attr"`
 }
 
 type Site struct {
 	XMLName xml.Name `xml:"site"`
 	Xmlns   string   `xml:"xmlns,attr"`
+
+	// ID is the ID of the blog within a WordPress.com or multisite
+	// network.
+	ID int `xml:",chardata"`
+}
+
+type Tag struct {
+	XMLName     xml.Name   `xml:"tag"`
+	TermID      int        `xml:"term_id"`
+	Slug        string     `xml:"tag_slug"`
+	Name        string     `xml:"tag_name"`
+	Description string     `xml:"tag_description"`
+	MetaKVs     []TermMeta `xml:"termmeta"`
 }
 
 type Term struct {
-	XMLName  xml.Name `xml:"term"`
-	ID       int      `xml:"term_id"`
-	Taxonomy string   `xml:"term_taxonomy"`
-	Slug     string   `xml:"term_slug"`
-	Parent   string   `xml:"term_parent"`
-	Name     string   `xml:"term_name"`
+	XMLName     xml.Name   `xml:"term"`
+	ID          int        `xml:"term_id"`
+	Taxonomy    string     `xml:"term_taxonomy"`
+	Slug        string     `xml:"term_slug"`
+	Parent      string     `xml:"term_parent"`
+	Name        string     `xml:"term_name"`
+	Description string     `xml:"term_description"`
+	MetaKVs     []TermMeta `xml:"termmeta"`
+}
+
+type TermMeta struct {
+	XMLName xml.Name `xml:"termmeta"`
+	Key     string   `xml:"meta_key"`
+	Value   string   `xml:"meta_value"`
 }
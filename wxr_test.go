@@ -57,6 +57,19 @@ const categoryValidFragment = `
 		<cat_name>A Category</cat_name>
 	</category>`
 
+const categoryDescribedFragment = `
+	<category>
+		<term_id>17</term_id>
+		<category_nicename>local</category_nicename>
+		<category_parent>news</category_parent>
+		<cat_name><![CDATA[Local]]></cat_name>
+		<category_description><![CDATA[News from <em>around here</em>]]></category_description>
+		<termmeta>
+			<meta_key>color</meta_key>
+			<meta_value><![CDATA[blue]]></meta_value>
+		</termmeta>
+	</category>`
+
 func TestDecodeCategory(t *testing.T) {
 	tests := []struct {
 		name string
@@ -70,6 +83,17 @@ func TestDecodeCategory(t *testing.T) {
 			Parent:   "",
 			Name:     "A Category"},
 		},
+		{"description and termmeta", categoryDescribedFragment, Category{
+			XMLName:     xml.Name{Local: "category"},
+			TermID:      17,
+			NiceName:    "local",
+			Parent:      "news",
+			Name:        "Local",
+			Description: "News from <em>around here</em>",
+			MetaKVs: []TermMeta{
+				{XMLName: xml.Name{Local: "termmeta"}, Key: "color", Value: "blue"},
+			}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -81,7 +105,69 @@ func TestDecodeCategory(t *testing.T) {
 				return
 			}
 
-			if got != tt.want {
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("xml.Unmarshal got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+const commentValidFragment = `
+	<comment>
+		<comment_id>42</comment_id>
+		<comment_author><![CDATA[A Commenter]]></comment_author>
+		<comment_author_email><![CDATA[commenter@example.com]]></comment_author_email>
+		<comment_author_url>https://commenter.example.com</comment_author_url>
+		<comment_author_IP><![CDATA[192.0.2.1]]></comment_author_IP>
+		<comment_date><![CDATA[2021-08-08 09:00:00]]></comment_date>
+		<comment_date_gmt><![CDATA[2021-08-08 14:00:00]]></comment_date_gmt>
+		<comment_content><![CDATA[Great post!]]></comment_content>
+		<comment_approved><![CDATA[1]]></comment_approved>
+		<comment_type><![CDATA[comment]]></comment_type>
+		<comment_parent>41</comment_parent>
+		<comment_user_id>0</comment_user_id>
+		<commentmeta>
+			<meta_key><![CDATA[akismet_result]]></meta_key>
+			<meta_value><![CDATA[false]]></meta_value>
+		</commentmeta>
+	</comment>`
+
+func TestDecodeComment(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Comment
+	}{
+		{"valid comment fragment", commentValidFragment, Comment{
+			XMLName:     xml.Name{Local: "comment"},
+			ID:          42,
+			Author:      "A Commenter",
+			AuthorEmail: "commenter@example.com",
+			AuthorURL:   "https://commenter.example.com",
+			AuthorIP:    "192.0.2.1",
+			Date:        "2021-08-08 09:00:00",
+			DateGMT:     "2021-08-08 14:00:00",
+			Content:     "Great post!",
+			Approved:    "1",
+			Type:        "comment",
+			Parent:      41,
+			UserID:      0,
+			MetaKVs: []CommentMeta{
+				{XMLName: xml.Name{Local: "commentmeta"}, Key: "akismet_result", Value: "false"},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Comment
+			err := xml.Unmarshal([]byte(tt.in), &got)
+			if err != nil {
+				t.Errorf("xml.Unmarshal failed for %s: %s", tt.name, err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("xml.Unmarshal got %+v, want %+v", got, tt.want)
 			}
 		})
@@ -134,7 +220,7 @@ func TestItem(t *testing.T) {
 			Link:            "example.com",
 			PubDate:         "Sun, 29 Nov 2020 16:29:33 +0000",
 			Creator:         "CreatorPerson",
-			GUID:            GUID{XMLName: xml.Name{Local: "guid"}, IsPermaLink: "false"},
+			GUID:            GUID{XMLName: xml.Name{Local: "guid"}, IsPermaLink: "false", Value: "example.com"},
 			Description:     "desc",
 			PostID:          9,
 			PostDate:        "2021-08-07 07:56:40",
@@ -144,17 +230,19 @@ func TestItem(t *testing.T) {
 			CommentStatus:   "closed",
 			PingStatus:      "closed",
 			PostName:        "home",
-			Status:          "publish",
+			RawStatus:       "publish",
 			PostParent:      0,
 			MenuOrder:       1,
 			PostType:        "nav_menu_item",
 			PostPassword:    "passwd",
-			IsSticky:        0,
-			Category:        ItemCategory{XMLName: xml.Name{Local: "category"}, Domain: "nav_menu", NiceName: "main-menu"},
+			Sticky:          0,
 			MetaKVs: []PostMeta{
 				{XMLName: xml.Name{Local: "postmeta"}, Key: "_menu_item_type", Value: "custom"},
 				{XMLName: xml.Name{Local: "postmeta"}, Key: "_menu_item_menu_item_parent", Value: "0"},
 			},
+			Category: []ItemCategory{
+				{XMLName: xml.Name{Local: "category"}, Domain: "nav_menu", NiceName: "main-menu", Name: "Main Menu"},
+			},
 		}},
 	}
 
@@ -175,6 +263,45 @@ func TestItem(t *testing.T) {
 	}
 }
 
+const itemCategoriesFragment = `
+	<item>
+		<category domain="category" nicename="go"><![CDATA[Go]]></category>
+		<category domain="post_tag" nicename="tips"><![CDATA[Tips & Tricks]]></category>
+		<category domain="category" nicename="tech"><![CDATA[Tech]]></category>
+		<category domain="series" nicename="intro"><![CDATA[Intro]]></category>
+	</item>
+`
+
+func TestItemCategories(t *testing.T) {
+	var item Item
+	if err := xml.Unmarshal([]byte(itemCategoriesFragment), &item); err != nil {
+		t.Fatalf("xml.Unmarshal failed: %s", err)
+	}
+
+	cat := func(domain, nicename, name string) ItemCategory {
+		return ItemCategory{XMLName: xml.Name{Local: "category"}, Domain: domain, NiceName: nicename, Name: name}
+	}
+
+	tests := []struct {
+		name string
+		got  []ItemCategory
+		want []ItemCategory
+	}{
+		{"Categories", item.Categories(), []ItemCategory{cat("category", "go", "Go"), cat("category", "tech", "Tech")}},
+		{"Tags", item.Tags(), []ItemCategory{cat("post_tag", "tips", "Tips & Tricks")}},
+		{"TermsOf custom taxonomy", item.TermsOf("series"), []ItemCategory{cat("series", "intro", "Intro")}},
+		{"TermsOf missing taxonomy", item.TermsOf("product_cat"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
 const postMetaValidFragment = `
 	<postmeta>
 		<meta_key>fruit</meta_key>
@@ -224,9 +351,11 @@ func TestDecodeSite(t *testing.T) {
 			// to hurt anything
 			XMLName: xml.Name{Space: "com-example:blah:1", Local: "site"},
 			Xmlns:   "com-example:blah:1",
+			ID:      1472,
 		}},
-		{"valid site", "<site>1472</site>", Site{
+		{"valid site", "<site> 1472 </site>", Site{
 			XMLName: xml.Name{Local: "site"},
+			ID:      1472,
 		}},
 	}
 
@@ -247,6 +376,39 @@ func TestDecodeSite(t *testing.T) {
 	}
 }
 
+const tagValidFragment = `
+	<tag>
+		<term_id>12</term_id>
+		<tag_slug>golang</tag_slug>
+		<tag_name><![CDATA[Go]]></tag_name>
+		<tag_description><![CDATA[Posts about the Go programming language]]></tag_description>
+		<termmeta>
+			<meta_key>featured</meta_key>
+			<meta_value>1</meta_value>
+		</termmeta>
+	</tag>`
+
+func TestDecodeTag(t *testing.T) {
+	var got Tag
+	if err := xml.Unmarshal([]byte(tagValidFragment), &got); err != nil {
+		t.Fatalf("xml.Unmarshal failed: %s", err)
+	}
+
+	want := Tag{
+		XMLName:     xml.Name{Local: "tag"},
+		TermID:      12,
+		Slug:        "golang",
+		Name:        "Go",
+		Description: "Posts about the Go programming language",
+		MetaKVs: []TermMeta{
+			{XMLName: xml.Name{Local: "termmeta"}, Key: "featured", Value: "1"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("xml.Unmarshal got %+v, want %+v", got, want)
+	}
+}
+
 const termValidFragment = `
 	<term>
 		<term_id>3</term_id>
@@ -256,6 +418,18 @@ const termValidFragment = `
 		<term_name>Category</term_name>
 	</term>`
 
+const termDescribedFragment = `
+	<term>
+		<term_id>8</term_id>
+		<term_taxonomy>series</term_taxonomy>
+		<term_slug>go-basics</term_slug>
+		<term_parent></term_parent>
+		<term_name>Go Basics</term_name>
+		<term_description>A series for beginners</term_description>
+		<termmeta><meta_key>_order</meta_key><meta_value>1</meta_value></termmeta>
+		<termmeta><meta_key>_icon</meta_key><meta_value>gopher.png</meta_value></termmeta>
+	</term>`
+
 func TestDecodeTerm(t *testing.T) {
 	tests := []struct {
 		name string
@@ -270,6 +444,18 @@ func TestDecodeTerm(t *testing.T) {
 			Parent:   "none",
 			Name:     "Category"},
 		},
+		{"description and termmeta", termDescribedFragment, Term{
+			XMLName:     xml.Name{Local: "term"},
+			ID:          8,
+			Taxonomy:    "series",
+			Slug:        "go-basics",
+			Name:        "Go Basics",
+			Description: "A series for beginners",
+			MetaKVs: []TermMeta{
+				{XMLName: xml.Name{Local: "termmeta"}, Key: "_order", Value: "1"},
+				{XMLName: xml.Name{Local: "termmeta"}, Key: "_icon", Value: "gopher.png"},
+			}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -281,7 +467,7 @@ func TestDecodeTerm(t *testing.T) {
 				return
 			}
 
-			if got != tt.want {
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("xml.Unmarshal got %+v, want %+v", got, tt.want)
 			}
 		})